@@ -50,6 +50,11 @@ func main() {
 		Commands: []*cli.Command{
 			command.NewSyncCommand(),
 			command.NewForkCommand(),
+			command.NewListCommand(),
+			command.NewStatusCommand(),
+			command.NewExecCommand(),
+			command.NewGrepCommand(),
+			command.NewBranchCommand(),
 		},
 		Action: func(c *cli.Context) error {
 			cli.ShowAppHelpAndExit(c, 0)
@@ -7,6 +7,8 @@ require (
 	github.com/go-git/go-git/v5 v5.14.0
 	github.com/urfave/cli/v2 v2.27.5
 	gitlab.com/gitlab-org/api/client-go v0.124.0
+	golang.org/x/term v0.29.0
+	golang.org/x/time v0.10.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -36,7 +38,6 @@ require (
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/oauth2 v0.27.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
-	golang.org/x/time v0.10.0 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
 )
@@ -0,0 +1,199 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/dannydd88/gitup/internal/infra"
+	"github.com/dannydd88/gitup/pkg/gitup"
+
+	"github.com/dannydd88/dd-go"
+	"github.com/urfave/cli/v2"
+)
+
+func NewBranchCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "branch",
+		Usage: "Create, check out or delete the same branch in each local clone",
+		Subcommands: []*cli.Command{
+			newBranchSubcommand(
+				gitup.BranchCreate,
+				"Create a branch from default branch of origin or a given ref and check it out, dirty worktrees are skipped",
+				&cli.StringFlag{
+					Name:  "from",
+					Usage: "Revision to create the branch from such as a branch, tag or commit, origin/<default branch> if not set",
+				},
+			),
+			newBranchSubcommand(
+				gitup.BranchCheckout,
+				"Check out a branch, created from origin/<branch> if only that one exists, dirty worktrees are skipped",
+			),
+			newBranchSubcommand(
+				gitup.BranchDelete,
+				"Delete a branch which is not checked out",
+				&cli.BoolFlag{
+					Name:  "force",
+					Usage: "Delete the branch even if it is not merged",
+				},
+			),
+		},
+	}
+}
+
+func newBranchSubcommand(action, usage string, flags ...cli.Flag) *cli.Command {
+	return &cli.Command{
+		Name:      action,
+		Usage:     usage,
+		ArgsUsage: "<branch>",
+		Before:    infra.CommandInit,
+		Flags: slices.Concat(flags, []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "group",
+				Aliases: []string{"g"},
+				Usage:   "Groups to work on [higher priority than groups in yaml file]",
+			},
+			&cli.StringFlag{
+				Name:    "remote",
+				Aliases: []string{"r"},
+				Usage:   "Only work on clones of the remote with this name in config, work on all remotes if not set",
+			},
+			&cli.StringFlag{
+				Name:  "backend",
+				Usage: "Git backend to use, go-git | cli [higher priority than sync settings in yaml file]",
+			},
+			&cli.IntFlag{
+				Name:  "workers",
+				Usage: "Number of repos processed concurrently, number of cpu if not set [higher priority than limit settings in yaml file]",
+			},
+		}, newMatchFlags("work on"), newTimeoutFlags(), newResultFlags()),
+		Action: func(c *cli.Context) error {
+			// keep stdout for results only
+			infra.LogToStderr()
+			config := infra.GetConfig()
+
+			// ). check branch and repo config
+			if c.Args().Len() != 1 {
+				return fmt.Errorf("%s need exactly one branch, usage: %s %s", gitup.TagBranch, c.Command.HelpName, c.Command.ArgsUsage)
+			}
+			if config == nil || len(config.RepoConfigs) == 0 {
+				return fmt.Errorf("%s missing repo config", gitup.TagBranch)
+			}
+
+			// ). check report flags before any work
+			if err := checkReportFlags(c, gitup.TagBranch); err != nil {
+				return err
+			}
+
+			// ). select remotes to work on
+			remotes, err := config.SelectRemotes(c.String("remote"))
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagBranch, err)
+			}
+
+			// ). prepare repo matcher and git backend, sync section is optional here
+			syncConfig := config.SyncConfig
+			if syncConfig == nil {
+				syncConfig = &infra.SyncConfig{}
+			}
+			matcher, err := buildRepoMatcher(c, syncConfig)
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagBranch, err)
+			}
+			backend := syncConfig.Backend
+			if c.IsSet("backend") {
+				backend = dd.Ptr(c.String("backend"))
+			}
+
+			ctx, cancel, err := commandContext(c, gitup.TagBranch)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			// ). work on clones found under each remote subtree
+			var results []*gitup.RepoResult
+			for _, remote := range remotes {
+				if ctx.Err() != nil {
+					break
+				}
+
+				cwd := config.RemoteCwd(remote)
+				api, err := gitup.NewLocalList(&gitup.LocalConfig{
+					Root:   cwd,
+					Logger: infra.GetLogger(),
+				})
+				if err != nil {
+					return err
+				}
+
+				b := &gitup.Branch{
+					Api: api,
+					BranchConfig: &gitup.BranchConfig{
+						Groups:  selectGroups(c, remote, syncConfig),
+						Matcher: matcher,
+						Backend: backend,
+						Action:  action,
+						Name:    c.Args().First(),
+						From:    c.String("from"),
+						Force:   c.Bool("force"),
+					},
+					Cwd:        cwd,
+					TaskRunner: infra.GetWorkerPoolRunner(),
+					Logger:     infra.GetLogger(),
+				}
+				for _, r := range b.Go(ctx) {
+					r.Remote = dd.Val(remote.Name)
+					results = append(results, r)
+				}
+			}
+
+			if err := printBranchResults(os.Stdout, results); err != nil {
+				return err
+			}
+			if err := checkResults(c, gitup.TagBranch, results); err != nil {
+				return err
+			}
+			return checkCancelled(ctx, gitup.TagBranch)
+		},
+	}
+}
+
+func printBranchResults(w io.Writer, results []*gitup.RepoResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPO\tACTION\tRESULT")
+	done, skipped := 0, 0
+	for _, r := range results {
+		repo := r.Repo
+		if len(r.Remote) != 0 {
+			repo = r.Remote + ":" + r.Repo
+		}
+		var result string
+		switch {
+		case r.Err != nil:
+			result = "error: " + r.Err.Error()
+		case len(r.Skipped) != 0:
+			result = "skipped: " + r.Skipped
+			skipped++
+		default:
+			result = "done"
+			done++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", repo, r.Action, result)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(
+		w,
+		"%s total %d, done %d, skipped %d, failed %d\n",
+		gitup.TagBranch,
+		len(results),
+		done,
+		skipped,
+		len(results)-done-skipped,
+	)
+	return err
+}
@@ -0,0 +1,80 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/dannydd88/gitup/internal/infra"
+	"github.com/dannydd88/gitup/pkg/gitup"
+
+	"github.com/urfave/cli/v2"
+)
+
+func newTimeoutFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "timeout",
+			Usage: "Stop the whole run after this duration such as 30m or 2h, no timeout if not set",
+		},
+	}
+}
+
+// commandContext - Context of a command which is done on Ctrl-C, SIGTERM or flag timeout,
+//
+//	a second signal after done kills the process immediately
+func commandContext(c *cli.Context, tag string) (context.Context, context.CancelFunc, error) {
+	// ). cancel on signals
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	cancel := context.CancelFunc(stop)
+
+	// ). cancel on timeout
+	if existFlags(c, "timeout") {
+		timeout, err := gitup.ParseDuration(c.String("timeout"))
+		if err != nil {
+			stop()
+			return nil, nil, fmt.Errorf("%s %s", tag, err)
+		}
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		cancel = func() {
+			cancelTimeout()
+			stop()
+		}
+	}
+
+	// ). restore default signal behavior once done, nothing to tell if finished normally
+	finished := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		stop()
+		select {
+		case <-finished:
+			return
+		default:
+		}
+		if errors.Is(ctx.Err(), context.Canceled) {
+			infra.GetLogger().Warn(tag, "Interrupted, waiting running repos to stop, press Ctrl-C again to force quit")
+		} else {
+			infra.GetLogger().Warn(tag, "Timeout, waiting running repos to stop")
+		}
+	}()
+	return ctx, func() {
+		close(finished)
+		cancel()
+	}, nil
+}
+
+// checkCancelled - Error if |ctx| is interrupted or timeout
+func checkCancelled(ctx context.Context, tag string) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%s timeout", tag)
+	case ctx.Err() != nil:
+		return fmt.Errorf("%s interrupted", tag)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/dannydd88/gitup/internal/infra"
+	"github.com/dannydd88/gitup/pkg/gitup"
+
+	"github.com/dannydd88/dd-go"
+	"github.com/urfave/cli/v2"
+)
+
+func NewExecCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "exec",
+		Usage:     "Run a command in each local clone",
+		ArgsUsage: "-- <command> [args...]",
+		Before:    infra.CommandInit,
+		Flags: append([]cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "group",
+				Aliases: []string{"g"},
+				Usage:   "Groups to run in [higher priority than groups in yaml file]",
+			},
+			&cli.StringFlag{
+				Name:    "remote",
+				Aliases: []string{"r"},
+				Usage:   "Only run in clones of the remote with this name in config, run in all remotes if not set",
+			},
+			&cli.BoolFlag{
+				Name:  "ordered",
+				Usage: "Print output of repos in path order instead of finishing order",
+			},
+			&cli.BoolFlag{
+				Name:  "fail-fast",
+				Usage: "Start no more repo after the first failure, running ones are not interrupted",
+			},
+			&cli.StringFlag{
+				Name:  "repo-timeout",
+				Usage: "Kill the command in one repo after this duration such as 10m, no timeout if not set",
+			},
+			&cli.IntFlag{
+				Name:  "workers",
+				Usage: "Number of repos processed concurrently, number of cpu if not set [higher priority than limit settings in yaml file]",
+			},
+		}, slices.Concat(
+			newMatchFlags("run in"),
+			newTimeoutFlags(),
+			newResultFlags(),
+		)...),
+		Action: func(c *cli.Context) error {
+			// keep stdout for output of command only
+			infra.LogToStderr()
+			config := infra.GetConfig()
+
+			// ). check command and repo config
+			if c.Args().Len() == 0 {
+				return fmt.Errorf("%s missing command, usage: %s %s", gitup.TagExec, c.Command.HelpName, c.Command.ArgsUsage)
+			}
+			if config == nil || len(config.RepoConfigs) == 0 {
+				return fmt.Errorf("%s missing repo config", gitup.TagExec)
+			}
+
+			// ). check report flags before any work
+			if err := checkReportFlags(c, gitup.TagExec); err != nil {
+				return err
+			}
+
+			// ). select remotes to run in
+			remotes, err := config.SelectRemotes(c.String("remote"))
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagExec, err)
+			}
+
+			// ). prepare repo matcher, sync section is optional here
+			syncConfig := config.SyncConfig
+			if syncConfig == nil {
+				syncConfig = &infra.SyncConfig{}
+			}
+			matcher, err := buildRepoMatcher(c, syncConfig)
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagExec, err)
+			}
+
+			// ). prepare timeouts and cancellation by signals
+			var repoTimeout time.Duration
+			if existFlags(c, "repo-timeout") {
+				if repoTimeout, err = gitup.ParseDuration(c.String("repo-timeout")); err != nil {
+					return fmt.Errorf("%s %s", gitup.TagExec, err)
+				}
+			}
+			ctx, cancel, err := commandContext(c, gitup.TagExec)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			// ). run in clones found under each remote subtree
+			var results []*gitup.RepoResult
+			for _, remote := range remotes {
+				if ctx.Err() != nil || (c.Bool("fail-fast") && anyFailed(results)) {
+					break
+				}
+
+				cwd := config.RemoteCwd(remote)
+				api, err := gitup.NewLocalList(&gitup.LocalConfig{
+					Root:   cwd,
+					Logger: infra.GetLogger(),
+				})
+				if err != nil {
+					return err
+				}
+
+				e := &gitup.Exec{
+					Api: api,
+					ExecConfig: &gitup.ExecConfig{
+						Groups:      selectGroups(c, remote, syncConfig),
+						Matcher:     matcher,
+						Command:     c.Args().Slice(),
+						Ordered:     c.Bool("ordered"),
+						FailFast:    c.Bool("fail-fast"),
+						RepoTimeout: repoTimeout,
+					},
+					Cwd:        cwd,
+					TaskRunner: infra.GetWorkerPoolRunner(),
+					Logger:     infra.GetLogger(),
+					Remote:     dd.Val(remote.Name),
+					Output:     os.Stdout,
+				}
+				results = append(results, e.Go(ctx)...)
+			}
+
+			if err := checkResults(c, gitup.TagExec, results); err != nil {
+				return err
+			}
+			return checkCancelled(ctx, gitup.TagExec)
+		},
+	}
+}
+
+func anyFailed(results []*gitup.RepoResult) bool {
+	return slices.ContainsFunc(results, (*gitup.RepoResult).Failed)
+}
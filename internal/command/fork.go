@@ -3,6 +3,7 @@ package command
 import (
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/dannydd88/gitup/internal/infra"
 	"github.com/dannydd88/gitup/pkg/gitup"
@@ -17,7 +18,7 @@ func NewForkCommand() *cli.Command {
 		Name:   "fork",
 		Usage:  "Fork repo via config or flags",
 		Before: infra.CommandInit,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:    "from-group",
 				Aliases: []string{"fg"},
@@ -42,22 +43,59 @@ func NewForkCommand() *cli.Command {
 				Name:  "forks",
 				Usage: "Fork config yaml file",
 			},
+			&cli.StringFlag{
+				Name:  "remote",
+				Usage: "Target remote's name in config, can be null if there is only one remote",
+			},
 			&cli.BoolFlag{
 				Name:    "rm-fork-relation",
 				Aliases: []string{"rfr"},
 				Usage:   "Remove fork relationship",
 			},
-		},
+		}, slices.Concat(
+			newTimeoutFlags(),
+			newLimitFlags(),
+			newRetryFlags(),
+			newProgressFlags(),
+			newResultFlags(),
+		)...),
 		Action: func(ctx *cli.Context) error {
 			config := infra.GetConfig()
+			progress, err := buildProgress(ctx, gitup.TagFork)
+			if err != nil {
+				return err
+			}
+			if progress != nil {
+				// stop early on error, stopping twice is fine
+				defer progress.Stop()
+			}
 
 			// ). check repo config
-			if config == nil || config.RepoConfig == nil {
+			if config == nil || len(config.RepoConfigs) == 0 {
 				return fmt.Errorf("%s missing repo config", gitup.TagFork)
 			}
 
+			// ). check report flags before any work
+			if err := checkReportFlags(ctx, gitup.TagFork); err != nil {
+				return err
+			}
+
+			// ). select the only remote to fork in
+			remotes, err := config.SelectRemotes(ctx.String("remote"))
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagFork, err)
+			}
+			if len(remotes) != 1 {
+				return fmt.Errorf("%s ERROR: should provide flag %s when there are several remotes", gitup.TagFork, "--remote")
+			}
+
 			// ). decide repository type
-			api, err := buildRepoFork(config.RepoConfig)
+			retry, err := buildRetryPolicy(ctx, config.RetryConfig)
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagFork, err)
+			}
+			limiter := buildHostLimiter(ctx, config.LimitConfig)
+			api, err := buildRepoFork(remotes[0], retry, limiter)
 			if err != nil {
 				return err
 			}
@@ -123,15 +161,27 @@ func NewForkCommand() *cli.Command {
 				)
 			}
 
-			// ). construct forker and run
-			(&gitup.Fork{
+			// ). construct forker and run until done, interrupted or timeout
+			runCtx, cancel, err := commandContext(ctx, gitup.TagFork)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+			results := (&gitup.Fork{
 				Api:         api,
 				ForkConfigs: forkConfigs,
 				TaskRunner:  infra.GetWorkerPoolRunner(),
 				Logger:      infra.GetLogger(),
-			}).Go()
+				Progress:    progress,
+			}).Go(runCtx)
+			if progress != nil {
+				progress.Stop()
+			}
 
-			return nil
+			if err := checkResults(ctx, gitup.TagFork, results); err != nil {
+				return err
+			}
+			return checkCancelled(runCtx, gitup.TagFork)
 		},
 	}
 }
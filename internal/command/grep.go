@@ -0,0 +1,238 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dannydd88/gitup/internal/infra"
+	"github.com/dannydd88/gitup/pkg/gitup"
+
+	"github.com/dannydd88/dd-go"
+	"github.com/urfave/cli/v2"
+)
+
+// grepEntry - one matched line in output of grep command
+type grepEntry struct {
+	Remote string `json:"remote,omitempty"`
+	Repo   string `json:"repo"`
+	Path   string `json:"path"`
+	Line   int    `json:"line"`
+	Text   string `json:"text"`
+	Server bool   `json:"server,omitempty"`
+}
+
+func NewGrepCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "grep",
+		Usage:     "Search pattern in files of local clones, or on server side for repos not cloned",
+		ArgsUsage: "<pattern>",
+		Before:    infra.CommandInit,
+		Flags: append([]cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "group",
+				Aliases: []string{"g"},
+				Usage:   "Groups to search [higher priority than groups in yaml file]",
+			},
+			&cli.StringFlag{
+				Name:    "remote",
+				Aliases: []string{"r"},
+				Usage:   "Only search the remote with this name in config, search all remotes if not set",
+			},
+			&cli.StringSliceFlag{
+				Name:  "path",
+				Usage: "Only search files whose path or base name match the glob such as *.go, can be repeated",
+			},
+			&cli.BoolFlag{
+				Name:    "ignore-case",
+				Aliases: []string{"i"},
+				Usage:   "Ignore case of pattern",
+			},
+			&cli.BoolFlag{
+				Name:    "fixed-strings",
+				Aliases: []string{"F"},
+				Usage:   "Treat pattern as plain text instead of regular expression",
+			},
+			&cli.BoolFlag{
+				Name:  "server",
+				Usage: "Also search repos listed by remote but not cloned via server side search api, pattern is sent as plain text, only gitlab is supported",
+			},
+			&cli.IntFlag{
+				Name:  "workers",
+				Usage: "Number of repos processed concurrently, number of cpu if not set [higher priority than limit settings in yaml file]",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Value:   "text",
+				Usage:   "Output format, text | json",
+			},
+		}, slices.Concat(
+			newMatchFlags("search"),
+			newTimeoutFlags(),
+		)...),
+		Action: func(c *cli.Context) error {
+			// keep stdout for matches only
+			infra.LogToStderr()
+			config := infra.GetConfig()
+
+			// ). check pattern and repo config
+			if c.Args().Len() != 1 {
+				return fmt.Errorf("%s need exactly one pattern, usage: %s %s", gitup.TagGrep, c.Command.HelpName, c.Command.ArgsUsage)
+			}
+			if config == nil || len(config.RepoConfigs) == 0 {
+				return fmt.Errorf("%s missing repo config", gitup.TagGrep)
+			}
+			output := strings.ToLower(c.String("output"))
+			if !slices.Contains([]string{"text", "json"}, output) {
+				return fmt.Errorf("%s unsupport output format -> %s", gitup.TagGrep, output)
+			}
+
+			// ). compile pattern
+			query := c.Args().First()
+			expr := query
+			if c.Bool("fixed-strings") {
+				expr = regexp.QuoteMeta(expr)
+			}
+			if c.Bool("ignore-case") {
+				expr = "(?i)" + expr
+			}
+			pattern, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagGrep, err)
+			}
+
+			// ). select remotes to search
+			remotes, err := config.SelectRemotes(c.String("remote"))
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagGrep, err)
+			}
+
+			// ). prepare repo matcher, sync section is optional here
+			syncConfig := config.SyncConfig
+			if syncConfig == nil {
+				syncConfig = &infra.SyncConfig{}
+			}
+			matcher, err := buildRepoMatcher(c, syncConfig)
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagGrep, err)
+			}
+			retry, err := buildRetryPolicy(c, config.RetryConfig)
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagGrep, err)
+			}
+
+			ctx, cancel, err := commandContext(c, gitup.TagGrep)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			// ). search each remote in its own subtree
+			var results []*gitup.GrepResult
+			for _, remote := range remotes {
+				if ctx.Err() != nil {
+					break
+				}
+
+				// ). repos listed by server in server mode, or clones found on disk
+				cwd := config.RemoteCwd(remote)
+				grep := &gitup.Grep{
+					GrepConfig: &gitup.GrepConfig{
+						Groups:  selectGroups(c, remote, syncConfig),
+						Matcher: matcher,
+						Pattern: pattern,
+						Paths:   c.StringSlice("path"),
+						Query:   dd.Ptr(query),
+					},
+					Cwd:        cwd,
+					TaskRunner: infra.GetWorkerPoolRunner(),
+					Logger:     infra.GetLogger(),
+				}
+				if c.Bool("server") {
+					search, err := buildRepoSearch(remote, retry, buildHostLimiter(c, config.LimitConfig))
+					if err != nil {
+						return fmt.Errorf("%s %s", gitup.TagGrep, err)
+					}
+					grep.Api = search
+					grep.Search = search
+				} else {
+					grep.Api, err = gitup.NewLocalList(&gitup.LocalConfig{
+						Root:   cwd,
+						Logger: infra.GetLogger(),
+					})
+					if err != nil {
+						return err
+					}
+				}
+
+				for _, r := range grep.Go(ctx) {
+					r.Remote = dd.Val(remote.Name)
+					results = append(results, r)
+				}
+			}
+
+			// ). print, then fail if any repo cannot be searched
+			if err := printGrepResults(os.Stdout, output, results); err != nil {
+				return err
+			}
+			repos, matches, failed := 0, 0, 0
+			for _, r := range results {
+				if r.Err != nil {
+					failed++
+					infra.GetLogger().Error(gitup.TagGrep, "Failed", "[", r.Repo, "]", r.Err)
+					continue
+				}
+				repos++
+				matches += len(r.Matches)
+			}
+			infra.GetLogger().Info(gitup.TagGrep, "Summary -> repos", repos, ", matches", matches, ", failed", failed)
+			if failed != 0 {
+				return fmt.Errorf("%s %d repos failed to search", gitup.TagGrep, failed)
+			}
+			return checkCancelled(ctx, gitup.TagGrep)
+		},
+	}
+}
+
+// printGrepResults - Print matches as "<remote>/<repo>/<path>:<line>:<text>" in text format,
+// which is the path relative to cwd in config for local clones
+func printGrepResults(w io.Writer, format string, results []*gitup.GrepResult) error {
+	var entries []*grepEntry
+	for _, r := range results {
+		for _, m := range r.Matches {
+			entries = append(entries, &grepEntry{
+				Remote: r.Remote,
+				Repo:   r.Repo,
+				Path:   m.Path,
+				Line:   m.Line,
+				Text:   m.Text,
+				Server: r.Server,
+			})
+		}
+	}
+
+	switch format {
+	case "json":
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		if entries == nil {
+			entries = []*grepEntry{}
+		}
+		return e.Encode(entries)
+	case "text":
+		for _, e := range entries {
+			if _, err := fmt.Fprintf(w, "%s:%d:%s\n", path.Join(e.Remote, e.Repo, e.Path), e.Line, e.Text); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s unsupport output format -> %s", gitup.TagGrep, format)
+	}
+}
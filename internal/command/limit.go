@@ -0,0 +1,53 @@
+package command
+
+import (
+	"github.com/dannydd88/gitup/internal/infra"
+	"github.com/dannydd88/gitup/pkg/gitup"
+
+	"github.com/urfave/cli/v2"
+)
+
+func newLimitFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:  "workers",
+			Usage: "Number of repos processed concurrently, number of cpu if not set [higher priority than limit settings in yaml file]",
+		},
+		&cli.Float64Flag{
+			Name:  "rps",
+			Usage: "Api requests and git operations per second to each host, 0 means unlimited [higher priority than limit settings in yaml file]",
+		},
+		&cli.IntFlag{
+			Name:  "max-clones",
+			Usage: "Concurrent git clone, fetch or pull to each host, 0 means unlimited [higher priority than limit settings in yaml file]",
+		},
+	}
+}
+
+// buildHostLimiter - build |HostLimiter| from flags or limit section, nil if nothing is limited
+func buildHostLimiter(c *cli.Context, config *infra.LimitConfig) *gitup.HostLimiter {
+	lc := &gitup.LimitConfig{}
+	if config != nil {
+		lc.RPS = config.RPS
+		lc.Clones = config.Clones
+		lc.Hosts = map[string]*gitup.HostLimitConfig{}
+		for h, l := range config.Hosts {
+			if l == nil {
+				continue
+			}
+			lc.Hosts[h] = &gitup.HostLimitConfig{
+				RPS:    l.RPS,
+				Clones: l.Clones,
+			}
+		}
+	}
+
+	// higher priority to use cli flag
+	if c.IsSet("rps") {
+		lc.RPS = c.Float64("rps")
+	}
+	if c.IsSet("max-clones") {
+		lc.Clones = c.Int("max-clones")
+	}
+	return gitup.NewHostLimiter(lc)
+}
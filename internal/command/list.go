@@ -0,0 +1,254 @@
+package command
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dannydd88/gitup/internal/infra"
+	"github.com/dannydd88/gitup/pkg/gitup"
+
+	"github.com/dannydd88/dd-go"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// listEntry - one repo in output of list command
+type listEntry struct {
+	Remote        string   `json:"remote,omitempty" yaml:"remote,omitempty"`
+	ID            int      `json:"id" yaml:"id"`
+	FullPath      string   `json:"full_path" yaml:"full_path"`
+	URL           string   `json:"url" yaml:"url"`
+	SSHURL        string   `json:"ssh_url,omitempty" yaml:"ssh_url,omitempty"`
+	DefaultBranch string   `json:"default_branch,omitempty" yaml:"default_branch,omitempty"`
+	Archived      bool     `json:"archived" yaml:"archived"`
+	Visibility    string   `json:"visibility,omitempty" yaml:"visibility,omitempty"`
+	Language      string   `json:"language,omitempty" yaml:"language,omitempty"`
+	Topics        []string `json:"topics,omitempty" yaml:"topics,omitempty"`
+	LastActivity  string   `json:"last_activity,omitempty" yaml:"last_activity,omitempty"`
+}
+
+func NewListCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "list",
+		Usage:  "List repos of remotes without syncing them",
+		Before: infra.CommandInit,
+		Flags: append([]cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "group",
+				Aliases: []string{"g"},
+				Usage:   "Groups to list [higher priority than groups in yaml file], list all visible repos if no group is set anywhere",
+			},
+			&cli.StringFlag{
+				Name:    "remote",
+				Aliases: []string{"r"},
+				Usage:   "Only list the remote with this name in config, list all remotes if not set",
+			},
+			&cli.StringSliceFlag{
+				Name:  "topic",
+				Usage: "Only list repos with any of these topics, can be repeated [higher priority than sync settings in yaml file]",
+			},
+			&cli.StringFlag{
+				Name:  "visibility",
+				Usage: "Only list repos with these comma separated visibilities, such as public,internal [higher priority than sync settings in yaml file]",
+			},
+			&cli.StringSliceFlag{
+				Name:  "language",
+				Usage: "Only list repos with any of these primary languages, can be repeated, repos whose language is unknown are kept [higher priority than sync settings in yaml file]",
+			},
+			&cli.StringFlag{
+				Name:  "active-within",
+				Usage: "Only list repos with activity within this duration, such as 180d or 12h [higher priority than sync settings in yaml file]",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Value:   "table",
+				Usage:   "Output format, table | json | yaml | csv",
+			},
+		}, slices.Concat(
+			newMatchFlags("list"),
+			newTimeoutFlags(),
+		)...),
+		Action: func(c *cli.Context) error {
+			// keep stdout for the inventory only
+			infra.LogToStderr()
+			config := infra.GetConfig()
+
+			// ). check repo config
+			if config == nil || len(config.RepoConfigs) == 0 {
+				return fmt.Errorf("%s missing repo config", gitup.TagList)
+			}
+
+			// ). check output format before any request
+			output := strings.ToLower(c.String("output"))
+			if !slices.Contains([]string{"table", "json", "yaml", "csv"}, output) {
+				return fmt.Errorf("%s unsupport output format -> %s", gitup.TagList, output)
+			}
+
+			// ). select remotes to list
+			remotes, err := config.SelectRemotes(c.String("remote"))
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagList, err)
+			}
+
+			// ). prepare repo matcher, sync section is optional here
+			syncConfig := config.SyncConfig
+			if syncConfig == nil {
+				syncConfig = &infra.SyncConfig{}
+			}
+			matcher, err := buildRepoMatcher(c, syncConfig)
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagList, err)
+			}
+			retry, err := buildRetryPolicy(c, config.RetryConfig)
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagList, err)
+			}
+
+			ctx, cancel, err := commandContext(c, gitup.TagList)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			// ). list each remote
+			var entries []*listEntry
+			failed := 0
+			for _, remote := range remotes {
+				if ctx.Err() != nil {
+					break
+				}
+
+				api, err := buildRepoList(remote, retry, buildHostLimiter(c, config.LimitConfig))
+				if err != nil {
+					return err
+				}
+
+				l := &gitup.List{
+					Api: api,
+					ListConfig: &gitup.ListConfig{
+						Groups:  selectGroups(c, remote, syncConfig),
+						Matcher: matcher,
+					},
+					Logger: infra.GetLogger(),
+				}
+				repos, failures := l.Go(ctx)
+				for _, r := range repos {
+					entries = append(entries, newListEntry(dd.Val(remote.Name), r))
+				}
+				failed += len(failures)
+			}
+
+			// ). print, then fail if any group failed to list
+			if err := printListEntries(os.Stdout, output, entries); err != nil {
+				return err
+			}
+			if err := checkCancelled(ctx, gitup.TagList); err != nil {
+				return err
+			}
+			if failed != 0 {
+				return fmt.Errorf("%s %d listings failed", gitup.TagList, failed)
+			}
+			return nil
+		},
+	}
+}
+
+func newListEntry(remote string, r *gitup.Repo) *listEntry {
+	e := &listEntry{
+		Remote:        remote,
+		ID:            r.ID,
+		FullPath:      r.FullPath,
+		URL:           r.URL,
+		SSHURL:        r.SSHURL,
+		DefaultBranch: r.DefaultBranch,
+		Archived:      r.Archived,
+		Visibility:    r.Visibility,
+		Language:      r.Language,
+		Topics:        r.Topics,
+	}
+	if !r.LastActivity.IsZero() {
+		e.LastActivity = r.LastActivity.UTC().Format(time.RFC3339)
+	}
+	return e
+}
+
+func printListEntries(w io.Writer, format string, entries []*listEntry) error {
+	if entries == nil {
+		entries = []*listEntry{}
+	}
+	switch format {
+	case "json":
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(entries)
+	case "yaml":
+		e := yaml.NewEncoder(w)
+		e.SetIndent(2)
+		if err := e.Encode(entries); err != nil {
+			return err
+		}
+		return e.Close()
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{
+			"remote",
+			"id",
+			"full_path",
+			"url",
+			"ssh_url",
+			"default_branch",
+			"archived",
+			"visibility",
+			"language",
+			"topics",
+			"last_activity",
+		})
+		for _, e := range entries {
+			cw.Write([]string{
+				e.Remote,
+				strconv.Itoa(e.ID),
+				e.FullPath,
+				e.URL,
+				e.SSHURL,
+				e.DefaultBranch,
+				strconv.FormatBool(e.Archived),
+				e.Visibility,
+				e.Language,
+				strings.Join(e.Topics, ";"),
+				e.LastActivity,
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tREPO\tBRANCH\tARCHIVED\tLAST ACTIVITY\tURL")
+		for _, e := range entries {
+			repo := e.FullPath
+			if len(e.Remote) != 0 {
+				repo = e.Remote + ":" + e.FullPath
+			}
+			var archived string
+			if e.Archived {
+				archived = "yes"
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", e.ID, repo, e.DefaultBranch, archived, e.LastActivity, e.URL)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(w, "%s total %d\n", gitup.TagList, len(entries))
+		return err
+	default:
+		return fmt.Errorf("%s unsupport output format -> %s", gitup.TagList, format)
+	}
+}
@@ -0,0 +1,67 @@
+package command
+
+import (
+	"github.com/dannydd88/dd-go"
+	"github.com/dannydd88/gitup/internal/infra"
+	"github.com/dannydd88/gitup/pkg/gitup"
+
+	"github.com/urfave/cli/v2"
+)
+
+// newMatchFlags - include and exclude flags, |verb| tells what a command does to matched repos
+func newMatchFlags(verb string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "include",
+			Usage: "Only " + verb + " repos whose full path match the glob or \"re:\" prefixed regex, can be repeated [higher priority than sync settings in yaml file]",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "Skip repos whose full path match the glob or \"re:\" prefixed regex, can be repeated [higher priority than sync settings in yaml file]",
+		},
+	}
+}
+
+// buildRepoMatcher - build |RepoMatcher| from flags or sync section, nil if nothing to filter
+func buildRepoMatcher(c *cli.Context, config *infra.SyncConfig) (*gitup.RepoMatcher, error) {
+	mc := &gitup.MatcherConfig{
+		Include:      config.Include,
+		Exclude:      config.Exclude,
+		Topics:       config.Topics,
+		Languages:    config.Languages,
+		ActiveWithin: config.ActiveWithin,
+	}
+	if config.Visibility != nil {
+		mc.Visibility = []*string{config.Visibility}
+	}
+
+	// higher priority to use cli flag
+	if existFlags(c, "include") {
+		mc.Include = dd.PtrSlice(c.StringSlice("include"))
+	}
+	if existFlags(c, "exclude") {
+		mc.Exclude = dd.PtrSlice(c.StringSlice("exclude"))
+	}
+	if existFlags(c, "topic") {
+		mc.Topics = dd.PtrSlice(c.StringSlice("topic"))
+	}
+	if existFlags(c, "visibility") {
+		mc.Visibility = []*string{dd.Ptr(c.String("visibility"))}
+	}
+	if existFlags(c, "language") {
+		mc.Languages = dd.PtrSlice(c.StringSlice("language"))
+	}
+	if existFlags(c, "active-within") {
+		mc.ActiveWithin = dd.Ptr(c.String("active-within"))
+	}
+
+	if len(mc.Include) == 0 &&
+		len(mc.Exclude) == 0 &&
+		len(mc.Topics) == 0 &&
+		len(mc.Visibility) == 0 &&
+		len(mc.Languages) == 0 &&
+		mc.ActiveWithin == nil {
+		return nil, nil
+	}
+	return gitup.NewRepoMatcher(mc)
+}
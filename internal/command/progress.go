@@ -0,0 +1,60 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dannydd88/gitup/internal/infra"
+	"github.com/dannydd88/gitup/pkg/gitup"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+func newProgressFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "progress",
+			Value: "auto",
+			Usage: "Progress display, auto | plain, auto shows live progress when stdout is a terminal and plain log lines otherwise",
+		},
+	}
+}
+
+// buildProgress - Live progress on terminal stdout, nil for plain log lines,
+//
+//	logs are printed above the progress, so call it before anything takes the logger
+func buildProgress(c *cli.Context, tag string) (gitup.Progress, error) {
+	switch strings.ToLower(c.String("progress")) {
+	case "auto", "":
+	case "plain":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%s unsupport progress -> %s", tag, c.String("progress"))
+	}
+
+	// ). only draw on an interactive terminal
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, nil
+	}
+	p := gitup.NewTerminalProgress(os.Stdout, tag, func() int {
+		w, _, err := term.GetSize(fd)
+		if err != nil {
+			return 0
+		}
+		return w
+	})
+	infra.LogTo(p)
+	return p, nil
+}
+
+// pauseProgress - Run |fn| without |progress| redrawing over it, such as a prompt
+func pauseProgress(progress gitup.Progress, fn func()) {
+	if p, ok := progress.(*gitup.TerminalProgress); ok {
+		p.Pause(fn)
+		return
+	}
+	fn()
+}
@@ -8,9 +8,11 @@ import (
 	"github.com/dannydd88/gitup/pkg/gitup"
 
 	"github.com/dannydd88/dd-go"
+	"github.com/urfave/cli/v2"
 )
 
-func buildRepoList(config *infra.RepoConfig) (gitup.RepoList, error) {
+// buildRepoList - |retry| and |limiter| are applied to api requests of providers supporting them
+func buildRepoList(config *infra.RepoConfig, retry *gitup.RetryPolicy, limiter *gitup.HostLimiter) (gitup.RepoList, error) {
 	var instance gitup.RepoList
 	var e error
 	switch strings.ToLower(dd.Val(config.Type)) {
@@ -19,15 +21,68 @@ func buildRepoList(config *infra.RepoConfig) (gitup.RepoList, error) {
 			Host:           config.Host,
 			Token:          config.Token,
 			FilterArchived: config.FilterArchived,
+			Retry:          retry,
+			Limiter:        limiter,
 			Logger:         infra.GetLogger(),
 		})
+	case "github":
+		instance, e = gitup.NewGithubList(&gitup.GithubConfig{
+			Host:           config.Host,
+			Token:          config.Token,
+			FilterArchived: config.FilterArchived,
+			Retry:          retry,
+			Limiter:        limiter,
+			Logger:         infra.GetLogger(),
+		})
+	case "gitea", "forgejo":
+		instance, e = gitup.NewGiteaList(&gitup.GiteaConfig{
+			Host:           config.Host,
+			Token:          config.Token,
+			FilterArchived: config.FilterArchived,
+			Retry:          retry,
+			Limiter:        limiter,
+			Logger:         infra.GetLogger(),
+		})
+	case "bitbucket-server":
+		instance, e = gitup.NewBitbucketList(&gitup.BitbucketConfig{
+			Host:           config.Host,
+			Token:          config.Token,
+			FilterArchived: config.FilterArchived,
+			Retry:          retry,
+			Limiter:        limiter,
+			Logger:         infra.GetLogger(),
+		})
+	case "static":
+		var repos []*gitup.StaticRepo
+		for _, r := range config.Repos {
+			repos = append(repos, &gitup.StaticRepo{
+				URL:   r.URL,
+				Group: r.Group,
+				Name:  r.Name,
+			})
+		}
+		instance, e = gitup.NewStaticList(&gitup.StaticConfig{
+			Repos:  repos,
+			Logger: infra.GetLogger(),
+		})
+	case "local":
+		// walk |path| if provided, otherwise the tree under |cwd|
+		root := config.Path
+		if root == nil {
+			root = infra.GetConfig().RemoteCwd(config)
+		}
+		instance, e = gitup.NewLocalList(&gitup.LocalConfig{
+			Root:   root,
+			Logger: infra.GetLogger(),
+		})
 	default:
 		return nil, fmt.Errorf("unsupport repostory type")
 	}
 	return instance, e
 }
 
-func buildRepoFork(config *infra.RepoConfig) (gitup.RepoFork, error) {
+// buildRepoFork - |retry| and |limiter| are applied to api requests of providers supporting them
+func buildRepoFork(config *infra.RepoConfig, retry *gitup.RetryPolicy, limiter *gitup.HostLimiter) (gitup.RepoFork, error) {
 	var instance gitup.RepoFork
 	var e error
 	switch strings.ToLower(dd.Val(config.Type)) {
@@ -36,6 +91,26 @@ func buildRepoFork(config *infra.RepoConfig) (gitup.RepoFork, error) {
 			Host:           config.Host,
 			Token:          config.Token,
 			FilterArchived: config.FilterArchived,
+			Retry:          retry,
+			Limiter:        limiter,
+			Logger:         infra.GetLogger(),
+		})
+	case "github":
+		instance, e = gitup.NewGithubFork(&gitup.GithubConfig{
+			Host:           config.Host,
+			Token:          config.Token,
+			FilterArchived: config.FilterArchived,
+			Retry:          retry,
+			Limiter:        limiter,
+			Logger:         infra.GetLogger(),
+		})
+	case "gitea", "forgejo":
+		instance, e = gitup.NewGiteaFork(&gitup.GiteaConfig{
+			Host:           config.Host,
+			Token:          config.Token,
+			FilterArchived: config.FilterArchived,
+			Retry:          retry,
+			Limiter:        limiter,
 			Logger:         infra.GetLogger(),
 		})
 	default:
@@ -43,3 +118,38 @@ func buildRepoFork(config *infra.RepoConfig) (gitup.RepoFork, error) {
 	}
 	return instance, e
 }
+
+// buildRepoSearch - |retry| and |limiter| are applied to api requests of providers supporting them
+func buildRepoSearch(config *infra.RepoConfig, retry *gitup.RetryPolicy, limiter *gitup.HostLimiter) (gitup.RepoSearch, error) {
+	var instance gitup.RepoSearch
+	var e error
+	switch strings.ToLower(dd.Val(config.Type)) {
+	case "gitlab":
+		instance, e = gitup.NewGitlabSearch(&gitup.GitlabConfig{
+			Host:           config.Host,
+			Token:          config.Token,
+			FilterArchived: config.FilterArchived,
+			Retry:          retry,
+			Limiter:        limiter,
+			Logger:         infra.GetLogger(),
+		})
+	default:
+		return nil, fmt.Errorf("unsupport repostory type for server side search")
+	}
+	return instance, e
+}
+
+// selectGroups - Groups of |remote| to work on, from flag first, then the remote, then sync section,
+// empty means all visible repos
+func selectGroups(c *cli.Context, remote *infra.RepoConfig, config *infra.SyncConfig) []*string {
+	if existFlags(c, "group") {
+		return dd.PtrSlice(c.StringSlice("group"))
+	}
+	if len(remote.Groups) != 0 {
+		return remote.Groups
+	}
+	if config != nil {
+		return config.Groups
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dannydd88/gitup/internal/infra"
+	"github.com/dannydd88/gitup/pkg/gitup"
+
+	"github.com/urfave/cli/v2"
+)
+
+func newResultFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:  "max-failures",
+			Value: 0,
+			Usage: "Exit with error when failed repos are more than this, negative means never",
+		},
+		&cli.StringFlag{
+			Name:  "report",
+			Usage: "Write per repo results into a report file, json | junit | csv",
+		},
+		&cli.StringFlag{
+			Name:  "report-file",
+			Usage: "Path of report file, default to gitup-report.<json|xml|csv>",
+		},
+	}
+}
+
+// reportFile - Format and path of report file, empty format if flag report is not set
+func reportFile(ctx *cli.Context) (string, string) {
+	if !existFlags(ctx, "report") {
+		return "", ""
+	}
+	format := strings.ToLower(ctx.String("report"))
+	path := ctx.String("report-file")
+	if len(path) == 0 {
+		ext := format
+		if format == gitup.ReportJUnit {
+			ext = "xml"
+		}
+		path = fmt.Sprintf("gitup-report.%s", ext)
+	}
+	return format, path
+}
+
+// checkReportFlags - Check report format and that report file can be created, before any work,
+// so that a bad flag does not throw away results of a whole run, |tag| is used for error
+func checkReportFlags(ctx *cli.Context, tag string) error {
+	format, path := reportFile(ctx)
+	if len(format) == 0 {
+		return nil
+	}
+
+	// ). check format
+	if !slices.Contains([]string{gitup.ReportJSON, gitup.ReportJUnit, gitup.ReportCSV}, format) {
+		return fmt.Errorf("%s unsupport report format -> %s", tag, format)
+	}
+
+	// ). check path by opening it without truncating, and remove it again if it is new
+	_, statErr := os.Stat(path)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0o666)
+	if err != nil {
+		return fmt.Errorf("%s cannot create report file -> %s", tag, err)
+	}
+	f.Close()
+	if os.IsNotExist(statErr) {
+		os.Remove(path)
+	}
+	return nil
+}
+
+// writeReport - Write |results| if flag report is set, |tag| is used for logging
+func writeReport(ctx *cli.Context, tag string, results []*gitup.RepoResult) error {
+	format, path := reportFile(ctx)
+	if len(format) == 0 {
+		return nil
+	}
+
+	// ). render first, so that nothing is created on error
+	var b bytes.Buffer
+	name := fmt.Sprintf("%s %s", ctx.App.Name, ctx.Command.Name)
+	if err := gitup.WriteReport(&b, format, name, results); err != nil {
+		return err
+	}
+
+	// ). write report
+	if err := os.WriteFile(path, b.Bytes(), 0o666); err != nil {
+		return err
+	}
+
+	infra.GetLogger().Info(tag, "Write report ->", path)
+	return nil
+}
+
+// checkResults - Write report and summary of failures, error if there are too many failures
+func checkResults(ctx *cli.Context, tag string, results []*gitup.RepoResult) error {
+	// ). write report first, so that failures are always recorded
+	if err := writeReport(ctx, tag, results); err != nil {
+		return err
+	}
+
+	// ). summary of failures
+	failed := 0
+	for _, r := range results {
+		if !r.Failed() {
+			continue
+		}
+		failed++
+		infra.GetLogger().Error(tag, "Failed", "[", r.Repo, "]", r.Err)
+	}
+	infra.GetLogger().Info(tag, "Summary ->", "total", len(results), ", failed", failed)
+
+	// ). check threshold
+	max := ctx.Int("max-failures")
+	if max >= 0 && failed > max {
+		return fmt.Errorf("%s %d of %d repos failed", tag, failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package command
+
+import (
+	"github.com/dannydd88/gitup/internal/infra"
+	"github.com/dannydd88/gitup/pkg/gitup"
+
+	"github.com/dannydd88/dd-go"
+	"github.com/urfave/cli/v2"
+)
+
+func newRetryFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:  "retry",
+			Usage: "Total tries of git operations and api requests on transient failures, 1 means no retry [higher priority than retry settings in yaml file]",
+		},
+		&cli.StringFlag{
+			Name:  "retry-backoff",
+			Usage: "Wait before the first retry such as 2s, doubled on each retry [higher priority than retry settings in yaml file]",
+		},
+	}
+}
+
+// buildRetryPolicy - build |RetryPolicy| from flags or retry section, default policy if neither is set
+func buildRetryPolicy(c *cli.Context, config *infra.RetryConfig) (*gitup.RetryPolicy, error) {
+	rc := &gitup.RetryConfig{}
+	if config != nil {
+		rc.Attempts = config.Attempts
+		rc.Backoff = config.Backoff
+		rc.MaxBackoff = config.MaxBackoff
+		rc.RetryOn = config.RetryOn
+	}
+
+	// higher priority to use cli flag
+	if c.IsSet("retry") {
+		rc.Attempts = c.Int("retry")
+	}
+	if c.IsSet("retry-backoff") {
+		rc.Backoff = dd.Ptr(c.String("retry-backoff"))
+	}
+	return gitup.NewRetryPolicy(rc)
+}
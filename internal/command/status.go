@@ -0,0 +1,284 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dannydd88/gitup/internal/infra"
+	"github.com/dannydd88/gitup/pkg/gitup"
+
+	"github.com/dannydd88/dd-go"
+	"github.com/urfave/cli/v2"
+)
+
+// statusEntry - one repo in output of status command
+type statusEntry struct {
+	Remote        string `json:"remote,omitempty"`
+	Repo          string `json:"repo"`
+	Path          string `json:"path"`
+	Bare          bool   `json:"bare,omitempty"`
+	Branch        string `json:"branch,omitempty"`
+	Head          string `json:"head,omitempty"`
+	DefaultBranch string `json:"default_branch,omitempty"`
+	Upstream      string `json:"upstream,omitempty"`
+	Ahead         int    `json:"ahead"`
+	Behind        int    `json:"behind"`
+	Changed       int    `json:"changed"`
+	Untracked     int    `json:"untracked"`
+	Stashes       int    `json:"stashes"`
+	Missing       bool   `json:"missing,omitempty"`
+	Interesting   bool   `json:"interesting"`
+	Error         string `json:"error,omitempty"`
+}
+
+func NewStatusCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "status",
+		Usage:  "Show dirty, ahead, behind, off default branch or stashed local clones",
+		Before: infra.CommandInit,
+		Flags: append([]cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "group",
+				Aliases: []string{"g"},
+				Usage:   "Groups to check [higher priority than groups in yaml file]",
+			},
+			&cli.StringFlag{
+				Name:    "remote",
+				Aliases: []string{"r"},
+				Usage:   "Only check the remote with this name in config, check all remotes if not set",
+			},
+			&cli.BoolFlag{
+				Name:  "listed",
+				Usage: "Check repos listed by remotes instead of clones found on disk, clones not synced yet are reported as missing",
+			},
+			&cli.StringFlag{
+				Name:  "backend",
+				Usage: "Git backend to use, go-git | cli [higher priority than sync settings in yaml file]",
+			},
+			&cli.BoolFlag{
+				Name:    "interesting",
+				Aliases: []string{"i"},
+				Usage:   "Only show repos which are missing, dirty, ahead, behind, off default branch or have stashes",
+			},
+			&cli.IntFlag{
+				Name:  "workers",
+				Usage: "Number of repos processed concurrently, number of cpu if not set [higher priority than limit settings in yaml file]",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Value:   "text",
+				Usage:   "Output format, text | json",
+			},
+		}, slices.Concat(
+			newMatchFlags("check"),
+			newTimeoutFlags(),
+		)...),
+		Action: func(c *cli.Context) error {
+			// keep stdout for the status only
+			infra.LogToStderr()
+			config := infra.GetConfig()
+
+			// ). check repo config
+			if config == nil || len(config.RepoConfigs) == 0 {
+				return fmt.Errorf("%s missing repo config", gitup.TagStatus)
+			}
+
+			// ). check output format before any work
+			output := strings.ToLower(c.String("output"))
+			if !slices.Contains([]string{"text", "json"}, output) {
+				return fmt.Errorf("%s unsupport output format -> %s", gitup.TagStatus, output)
+			}
+
+			// ). select remotes to check
+			remotes, err := config.SelectRemotes(c.String("remote"))
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagStatus, err)
+			}
+
+			// ). prepare repo matcher and git backend, sync section is optional here
+			syncConfig := config.SyncConfig
+			if syncConfig == nil {
+				syncConfig = &infra.SyncConfig{}
+			}
+			matcher, err := buildRepoMatcher(c, syncConfig)
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagStatus, err)
+			}
+			backend := syncConfig.Backend
+			if c.IsSet("backend") {
+				backend = dd.Ptr(c.String("backend"))
+			}
+			retry, err := buildRetryPolicy(c, config.RetryConfig)
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagStatus, err)
+			}
+
+			ctx, cancel, err := commandContext(c, gitup.TagStatus)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			// ). check each remote in its own subtree
+			var entries []*statusEntry
+			for _, remote := range remotes {
+				if ctx.Err() != nil {
+					break
+				}
+
+				// ). repos listed by remote, or clones found on disk
+				cwd := config.RemoteCwd(remote)
+				var api gitup.RepoList
+				if c.Bool("listed") {
+					api, err = buildRepoList(remote, retry, buildHostLimiter(c, config.LimitConfig))
+				} else {
+					api, err = gitup.NewLocalList(&gitup.LocalConfig{
+						Root:   cwd,
+						Logger: infra.GetLogger(),
+					})
+				}
+				if err != nil {
+					return err
+				}
+
+				status := &gitup.Status{
+					Api: api,
+					StatusConfig: &gitup.StatusConfig{
+						Groups:  selectGroups(c, remote, syncConfig),
+						Backend: backend,
+						Matcher: matcher,
+					},
+					Cwd:        cwd,
+					TaskRunner: infra.GetWorkerPoolRunner(),
+					Logger:     infra.GetLogger(),
+				}
+				for _, r := range status.Go(ctx) {
+					r.Remote = dd.Val(remote.Name)
+					entries = append(entries, newStatusEntry(r))
+				}
+			}
+
+			// ). print, then fail if any repo cannot be checked
+			if c.Bool("interesting") {
+				entries = slices.DeleteFunc(entries, func(e *statusEntry) bool {
+					return !e.Interesting
+				})
+			}
+			if err := printStatusEntries(os.Stdout, output, entries); err != nil {
+				return err
+			}
+			failed := 0
+			for _, e := range entries {
+				if len(e.Error) != 0 {
+					failed++
+				}
+			}
+			if failed != 0 {
+				return fmt.Errorf("%s %d repos failed to check", gitup.TagStatus, failed)
+			}
+			return checkCancelled(ctx, gitup.TagStatus)
+		},
+	}
+}
+
+func newStatusEntry(r *gitup.RepoStatus) *statusEntry {
+	e := &statusEntry{
+		Remote:      r.Remote,
+		Repo:        r.Repo,
+		Path:        r.Path,
+		Missing:     r.Missing,
+		Interesting: r.Interesting(),
+	}
+	if r.Err != nil {
+		e.Error = r.Err.Error()
+	}
+	if s := r.Status; s != nil {
+		e.Bare = s.Bare
+		e.Branch = s.Branch
+		e.Head = s.Head
+		e.DefaultBranch = s.DefaultBranch
+		e.Upstream = s.Upstream
+		e.Ahead = s.Ahead
+		e.Behind = s.Behind
+		e.Changed = s.Changed
+		e.Untracked = s.Untracked
+		e.Stashes = s.Stashes
+	}
+	return e
+}
+
+// describe - Short description of what is interesting in |e|, "clean" if nothing
+func (e *statusEntry) describe() string {
+	switch {
+	case len(e.Error) != 0:
+		return "error: " + e.Error
+	case e.Missing:
+		return "missing"
+	}
+
+	var parts []string
+	if e.Changed != 0 || e.Untracked != 0 {
+		parts = append(parts, fmt.Sprintf("dirty(%d changed, %d untracked)", e.Changed, e.Untracked))
+	}
+	if e.Ahead != 0 {
+		parts = append(parts, fmt.Sprintf("ahead %d", e.Ahead))
+	}
+	if e.Behind != 0 {
+		parts = append(parts, fmt.Sprintf("behind %d", e.Behind))
+	}
+	if len(e.Branch) == 0 && len(e.Head) != 0 {
+		parts = append(parts, "detached at "+e.Head)
+	} else if len(e.Branch) != 0 && len(e.DefaultBranch) != 0 && e.Branch != e.DefaultBranch {
+		parts = append(parts, "off "+e.DefaultBranch)
+	}
+	if e.Stashes != 0 {
+		parts = append(parts, fmt.Sprintf("%d stashes", e.Stashes))
+	}
+	if len(parts) == 0 {
+		return "clean"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printStatusEntries(w io.Writer, format string, entries []*statusEntry) error {
+	switch format {
+	case "json":
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		if entries == nil {
+			entries = []*statusEntry{}
+		}
+		return e.Encode(entries)
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "REPO\tBRANCH\tUPSTREAM\tSTATE")
+		interesting := 0
+		for _, e := range entries {
+			repo := e.Repo
+			if len(e.Remote) != 0 {
+				repo = e.Remote + ":" + e.Repo
+			}
+			branch := e.Branch
+			if len(branch) == 0 && len(e.Head) != 0 {
+				branch = "(detached " + e.Head + ")"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", repo, branch, e.Upstream, e.describe())
+			if e.Interesting {
+				interesting++
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(w, "%s total %d, interesting %d\n", gitup.TagStatus, len(entries), interesting)
+		return err
+	default:
+		return fmt.Errorf("%s unsupport output format -> %s", gitup.TagStatus, format)
+	}
+}
@@ -1,10 +1,19 @@
 package command
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/dannydd88/dd-go"
 	"github.com/dannydd88/gitup/internal/infra"
+	"github.com/dannydd88/gitup/pkg/git"
 	"github.com/dannydd88/gitup/pkg/gitup"
 
 	"github.com/urfave/cli/v2"
@@ -15,66 +24,355 @@ func NewSyncCommand() *cli.Command {
 		Name:   "sync",
 		Usage:  "Sync repo via config",
 		Before: infra.CommandInit,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringSliceFlag{
 				Name:    "group",
 				Aliases: []string{"g"},
 				Usage:   "Groups that need to sync [higher priority than sync settings in yaml file]",
 			},
+			&cli.StringFlag{
+				Name:    "remote",
+				Aliases: []string{"r"},
+				Usage:   "Only sync the remote with this name in config, sync all remotes if not set",
+			},
 			&cli.BoolFlag{
 				Name:  "bare",
 				Usage: "Should sync repo in bare way",
 				Value: false,
 			},
-		},
+			&cli.StringFlag{
+				Name:  "backend",
+				Usage: "Git backend to use, go-git | cli [higher priority than sync settings in yaml file]",
+			},
+			&cli.IntFlag{
+				Name:  "depth",
+				Usage: "Clone and update with limited history depth, 0 means full history",
+			},
+			&cli.BoolFlag{
+				Name:  "single-branch",
+				Usage: "Only clone and update the default branch",
+			},
+			&cli.StringFlag{
+				Name:  "filter",
+				Usage: "Partial clone filter such as blob:none, only works with cli backend",
+			},
+			&cli.StringSliceFlag{
+				Name:  "topic",
+				Usage: "Only sync repos with any of these topics, can be repeated [higher priority than sync settings in yaml file]",
+			},
+			&cli.StringFlag{
+				Name:  "visibility",
+				Usage: "Only sync repos with these comma separated visibilities, such as public,internal [higher priority than sync settings in yaml file]",
+			},
+			&cli.StringSliceFlag{
+				Name:  "language",
+				Usage: "Only sync repos with any of these primary languages, can be repeated, repos whose language is unknown are kept [higher priority than sync settings in yaml file]",
+			},
+			&cli.StringFlag{
+				Name:  "active-within",
+				Usage: "Only sync repos with activity within this duration, such as 180d or 12h [higher priority than sync settings in yaml file]",
+			},
+			&cli.StringFlag{
+				Name:  "prune",
+				Usage: "What to do with local clones no longer listed, report | trash | delete [higher priority than sync settings in yaml file]",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Delete orphaned clones without confirmation when --prune delete",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only print what would be cloned, fetched, pulled or skipped",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Value:   "text",
+				Usage:   "Output format of dry run, text | json",
+			},
+			&cli.StringFlag{
+				Name:  "repo-timeout",
+				Usage: "Stop syncing one repo after this duration such as 10m, including retries, no timeout if not set",
+			},
+		}, slices.Concat(
+			newMatchFlags("sync"),
+			newTimeoutFlags(),
+			newLimitFlags(),
+			newRetryFlags(),
+			newProgressFlags(),
+			newResultFlags(),
+		)...),
 		Action: func(c *cli.Context) error {
 			config := infra.GetConfig()
+			var progress gitup.Progress
+			if c.Bool("dry-run") {
+				infra.LogToStderr()
+			} else {
+				var err error
+				if progress, err = buildProgress(c, gitup.TagSync); err != nil {
+					return err
+				}
+			}
+			if progress != nil {
+				// stop early on error, stopping twice is fine
+				defer progress.Stop()
+			}
 
 			// ). check repo config
-			if config == nil || config.RepoConfig == nil || config.SyncConfig == nil {
+			if config == nil || len(config.RepoConfigs) == 0 {
 				return fmt.Errorf("%s missing repo config", gitup.TagSync)
 			}
 
-			// ). decide repository type
-			api, err := buildRepoList(config.RepoConfig)
+			// ). check report flags before any work, dry run writes no report
+			if !c.Bool("dry-run") {
+				if err := checkReportFlags(c, gitup.TagSync); err != nil {
+					return err
+				}
+			}
+
+			// ). sync section is optional, all visible repos are synced if no group is set anywhere
+			syncSection := config.SyncConfig
+			if syncSection == nil {
+				syncSection = &infra.SyncConfig{}
+			}
+
+			// ). select remotes to sync
+			remotes, err := config.SelectRemotes(c.String("remote"))
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagSync, err)
+			}
+
+			// ). prepare repo matcher
+			matcher, err := buildRepoMatcher(c, syncSection)
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagSync, err)
+			}
+
+			// ). prepare retry policy
+			retry, err := buildRetryPolicy(c, config.RetryConfig)
+			if err != nil {
+				return fmt.Errorf("%s %s", gitup.TagSync, err)
+			}
+
+			// ). prepare per host limits
+			limiter := buildHostLimiter(c, config.LimitConfig)
+
+			// ). decide git backend, cli backend runs ssh in batch mode which never asks for a passphrase
+			backend := syncSection.Backend
+			if c.IsSet("backend") {
+				backend = dd.Ptr(c.String("backend"))
+			}
+			if strings.EqualFold(dd.Val(backend), git.BackendCLI) {
+				for _, remote := range remotes {
+					if strings.EqualFold(dd.Val(remote.Transport), git.TransportSSH) &&
+						remote.SSH != nil && len(dd.Val(remote.SSH.Passphrase)) != 0 {
+						name := dd.Val(remote.Name)
+						if len(name) == 0 {
+							name = dd.Val(remote.Host)
+						}
+						return fmt.Errorf(
+							"%s ssh passphrase is not supported by cli backend, use ssh-agent or go-git backend, remote -> %s",
+							gitup.TagSync,
+							name,
+						)
+					}
+				}
+			}
+
+			// ). prepare timeouts and cancellation by signals
+			var repoTimeout time.Duration
+			if existFlags(c, "repo-timeout") {
+				if repoTimeout, err = gitup.ParseDuration(c.String("repo-timeout")); err != nil {
+					return fmt.Errorf("%s %s", gitup.TagSync, err)
+				}
+			}
+			ctx, cancel, err := commandContext(c, gitup.TagSync)
 			if err != nil {
 				return err
 			}
+			defer cancel()
 
-			// ). check and build sync config
-			var syncConfig *gitup.SyncConfig
-			if existFlags(c, "group") {
-				// higher priority to use cli flag
-				syncConfig = &gitup.SyncConfig{
-					Token:  config.RepoConfig.Token,
-					Bare:   c.Bool("bare"),
-					Groups: dd.PtrSlice(c.StringSlice("group")),
+			// ). sync each remote into its own subtree
+			var plans []*gitup.SyncPlan
+			var results []*gitup.RepoResult
+			for _, remote := range remotes {
+				if ctx.Err() != nil {
+					break
 				}
-			} else if config.SyncConfig != nil {
-				syncConfig = &gitup.SyncConfig{
-					Token:  config.RepoConfig.Token,
-					Bare:   config.SyncConfig.Bare,
-					Groups: config.SyncConfig.Groups,
+
+				// ). decide repository type
+				api, err := buildRepoList(remote, retry, limiter)
+				if err != nil {
+					return err
 				}
-			} else {
-				return fmt.Errorf(
-					"%s ERROR: should provide sync info using cli flag[%s] or in config file section[%s]",
-					gitup.TagSync,
-					"--group",
-					"sync",
-				)
-			}
-
-			// ). construct syncer and run
-			(&gitup.Sync{
-				Api:        api,
-				SyncConfig: syncConfig,
-				Cwd:        config.Cwd,
-				TaskRunner: infra.GetWorkerPoolRunner(),
-				Logger:     infra.GetLogger(),
-			}).Go()
-
-			return nil
+
+				// ). build sync config, groups of cli flag first, then groups of remote, then sync section
+				syncConfig := &gitup.SyncConfig{
+					Token:  remote.Token,
+					Bare:   syncSection.Bare,
+					Groups: selectGroups(c, remote, syncSection),
+				}
+				if existFlags(c, "group") {
+					syncConfig.Bare = c.Bool("bare")
+				}
+
+				// ). apply transport of remote
+				syncConfig.Transport = remote.Transport
+				syncConfig.SSH = buildSSHConfig(remote.SSH)
+
+				// ). apply git backend and decide clone options
+				syncConfig.Backend = backend
+				syncConfig.Depth = syncSection.Depth
+				if c.IsSet("depth") {
+					syncConfig.Depth = c.Int("depth")
+				}
+				syncConfig.SingleBranch = syncSection.SingleBranch
+				if c.IsSet("single-branch") {
+					syncConfig.SingleBranch = c.Bool("single-branch")
+				}
+				syncConfig.Filter = syncSection.Filter
+				if c.IsSet("filter") {
+					syncConfig.Filter = dd.Ptr(c.String("filter"))
+				}
+				syncConfig.Matcher = matcher
+				syncConfig.Retry = retry
+				syncConfig.Limiter = limiter
+				syncConfig.RepoTimeout = repoTimeout
+				syncConfig.Prune = syncSection.Prune
+				if c.IsSet("prune") {
+					syncConfig.Prune = dd.Ptr(c.String("prune"))
+				}
+				syncConfig.ConfirmPrune = func(paths []string) bool {
+					if c.Bool("yes") {
+						return true
+					}
+					confirmed := false
+					pauseProgress(progress, func() {
+						confirmed = confirm(os.Stdin, os.Stderr, paths)
+					})
+					return confirmed
+				}
+
+				// ). construct syncer
+				syncer := &gitup.Sync{
+					Api:        api,
+					SyncConfig: syncConfig,
+					Cwd:        config.RemoteCwd(remote),
+					TaskRunner: infra.GetWorkerPoolRunner(),
+					Logger:     infra.GetLogger(),
+					Progress:   progress,
+				}
+
+				// ). only plan in dry run, run otherwise
+				if c.Bool("dry-run") {
+					for _, p := range syncer.Plan(ctx) {
+						p.Remote = dd.Val(remote.Name)
+						plans = append(plans, p)
+					}
+				} else {
+					for _, r := range syncer.Go(ctx) {
+						r.Remote = dd.Val(remote.Name)
+						results = append(results, r)
+					}
+				}
+			}
+
+			if c.Bool("dry-run") {
+				// ). print, then fail if any group failed to list
+				if err := printSyncPlans(os.Stdout, c.String("output"), plans); err != nil {
+					return err
+				}
+				if err := checkCancelled(ctx, gitup.TagSync); err != nil {
+					return err
+				}
+				failed := 0
+				for _, p := range plans {
+					if p.Action == gitup.ActionList {
+						failed++
+					}
+				}
+				if failed != 0 {
+					return fmt.Errorf("%s %d listings failed", gitup.TagSync, failed)
+				}
+				return nil
+			}
+			if progress != nil {
+				progress.Stop()
+			}
+			if err := checkResults(c, gitup.TagSync, results); err != nil {
+				return err
+			}
+			return checkCancelled(ctx, gitup.TagSync)
 		},
 	}
 }
+
+func buildSSHConfig(config *infra.SSHConfig) *git.SSHConfig {
+	if config == nil {
+		return nil
+	}
+	return &git.SSHConfig{
+		User:       config.User,
+		PrivateKey: config.PrivateKey,
+		Passphrase: config.Passphrase,
+		KnownHosts: config.KnownHosts,
+	}
+}
+
+func printSyncPlans(w io.Writer, format string, plans []*gitup.SyncPlan) error {
+	switch strings.ToLower(format) {
+	case "json":
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		if plans == nil {
+			plans = []*gitup.SyncPlan{}
+		}
+		return e.Encode(plans)
+	case "text", "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ACTION\tREPO\tPATH\tREASON")
+		counts := map[string]int{}
+		for _, p := range plans {
+			repo := p.Repo
+			if len(p.Remote) != 0 {
+				repo = p.Remote + ":" + p.Repo
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", p.Action, repo, p.Path, p.Reason)
+			counts[p.Action]++
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(
+			w,
+			"%s total %d, clone %d, move %d, fetch %d, pull %d, skip %d, orphan %d, list failed %d\n",
+			gitup.TagSync,
+			len(plans),
+			counts[git.ActionClone],
+			counts[gitup.ActionMove],
+			counts[git.ActionFetch],
+			counts[git.ActionPull],
+			counts[git.ActionSkip],
+			counts[gitup.ActionOrphan],
+			counts[gitup.ActionList],
+		)
+		return err
+	default:
+		return fmt.Errorf("%s unsupport output format -> %s", gitup.TagSync, format)
+	}
+}
+
+// confirm - Ask user to delete |paths|, false if |in| is not a terminal
+func confirm(in *os.File, out io.Writer, paths []string) bool {
+	if info, err := in.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	for _, p := range paths {
+		fmt.Fprintln(out, p)
+	}
+	fmt.Fprintf(out, "%s Delete %d directories above? [y/N] ", gitup.TagSync, len(paths))
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -1,11 +1,15 @@
 package infra
 
 import (
+	"io"
+	"os"
+
 	"github.com/dannydd88/dd-go"
 	"github.com/urfave/cli/v2"
 )
 
 type GitUpContext struct {
+	logLevel         dd.LogLevel
 	logger           dd.LevelLogger
 	config           *Config
 	workerPoolRunner *dd.WorkerPoolRunner
@@ -23,6 +27,7 @@ func AppInit(ctx *cli.Context) error {
 	if debug {
 		logLevel = dd.DEBUG
 	}
+	globalContext.logLevel = logLevel
 	globalContext.logger = dd.NewLevelLogger(logLevel)
 
 	globalContext.logger.Debug("[app]", "AppInit finish")
@@ -43,33 +48,58 @@ func CommandInit(ctx *cli.Context) error {
 	{
 		iniPath := ctx.String("ini-config")
 		profile := ctx.String("profile")
-		repoConfig := globalContext.config.RepoConfig
-		if repoConfig.Host == nil || repoConfig.Token == nil {
-			iniConfig := new(INIConfig)
-			err := dd.NewINILoader[INIConfig](dd.Ptr(iniPath), dd.Ptr(profile)).Load(iniConfig)
-			if err == nil {
-				if repoConfig.Host == nil {
-					repoConfig.Host = iniConfig.Host
-				}
-				if repoConfig.Token == nil {
-					repoConfig.Token = iniConfig.Token
+		var iniConfig *INIConfig
+		for _, repoConfig := range globalContext.config.RepoConfigs {
+			// other types either need no host and token, or default to their public host such as github.com
+			if !repoConfig.UseINI() || (repoConfig.Host != nil && repoConfig.Token != nil) {
+				continue
+			}
+			if iniConfig == nil {
+				iniConfig = new(INIConfig)
+				err := dd.NewINILoader[INIConfig](dd.Ptr(iniPath), dd.Ptr(profile)).Load(iniConfig)
+				if err != nil {
+					return err
 				}
-			} else {
-				return err
+			}
+			if repoConfig.Host == nil {
+				repoConfig.Host = iniConfig.Host
+			}
+			if repoConfig.Token == nil {
+				repoConfig.Token = iniConfig.Token
 			}
 		}
 	}
 
-	// ). init pool runner
+	// ). init pool runner, number of cpu workers if neither flag nor limit section is set
+	workers := 0
+	if globalContext.config.LimitConfig != nil {
+		workers = globalContext.config.LimitConfig.Workers
+	}
+	if ctx.IsSet("workers") {
+		workers = ctx.Int("workers")
+	}
 	globalContext.workerPoolRunner =
 		dd.NewWorkerPoolRunner(&dd.WorkerPoolRunnerOptions{
-			Logger: globalContext.logger,
+			Concurrency: uint(max(workers, 0)),
+			Logger:      globalContext.logger,
 		})
 
 	globalContext.logger.Debug("[app]", "CommandInit finish")
 	return nil
 }
 
+// LogToStderr - Move all logs to stderr, so that stdout only contains command output,
+//
+//	should be called before |GetLogger|
+func LogToStderr() {
+	LogTo(os.Stderr)
+}
+
+// LogTo - Move all logs to |w|, should be called before |GetLogger|
+func LogTo(w io.Writer) {
+	globalContext.logger = newWriterLogger(globalContext.logLevel, w)
+}
+
 func GetLogger() dd.LevelLogger {
 	return globalContext.logger
 }
@@ -1,24 +1,156 @@
 package infra
 
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dannydd88/dd-go"
+	"gopkg.in/yaml.v3"
+)
+
+// StaticRepoConfig - one repo entry of static repo setion of config.yaml
+type StaticRepoConfig struct {
+	URL   *string `yaml:"url"`
+	Group *string `yaml:"group,omitempty"`
+	Name  *string `yaml:"name,omitempty"`
+}
+
+// SSHConfig - ssh setion of repo setion of config.yaml
+type SSHConfig struct {
+	User       *string   `yaml:"user,omitempty"`
+	PrivateKey *string   `yaml:"private_key,omitempty"`
+	Passphrase *string   `yaml:"passphrase,omitempty"`
+	KnownHosts []*string `yaml:"known_hosts,omitempty"`
+}
+
 // RepoConfig - repo setion of config.yaml
 type RepoConfig struct {
-	Type           *string `yaml:"type"`
-	Host           *string `yaml:"host,omitempty"`
-	Token          *string `yaml:"token,omitempty"`
-	FilterArchived bool    `yaml:"filter_archived,omitempty"`
+	Name           *string             `yaml:"name,omitempty"`
+	Type           *string             `yaml:"type"`
+	Host           *string             `yaml:"host,omitempty"`
+	Token          *string             `yaml:"token,omitempty"`
+	FilterArchived bool                `yaml:"filter_archived,omitempty"`
+	Groups         []*string           `yaml:"groups,omitempty"`
+	Repos          []*StaticRepoConfig `yaml:"repos,omitempty"`
+	Path           *string             `yaml:"path,omitempty"`
+	Transport      *string             `yaml:"transport,omitempty"`
+	SSH            *SSHConfig          `yaml:"ssh,omitempty"`
+}
+
+// UseINI - Whether missing host and token of this repo come from ini config,
+// which only holds gitlab_host and gitlab_token, so only gitlab does
+func (r *RepoConfig) UseINI() bool {
+	return strings.EqualFold(dd.Val(r.Type), "gitlab")
+}
+
+// RepoConfigs - repo setion of config.yaml, either one remote or a list of named remotes
+type RepoConfigs []*RepoConfig
+
+// UnmarshalYAML - Accept both a single mapping and a sequence of mappings
+func (rs *RepoConfigs) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		r := new(RepoConfig)
+		if err := value.Decode(r); err != nil {
+			return err
+		}
+		*rs = RepoConfigs{r}
+		return nil
+	}
+	var list []*RepoConfig
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*rs = list
+	return nil
 }
 
 // SyncConfig - sync setion of config.yaml
 type SyncConfig struct {
-	Bare   bool      `yaml:"bare"`
-	Groups []*string `yaml:"groups,omitempty"`
+	Bare         bool      `yaml:"bare"`
+	Groups       []*string `yaml:"groups,omitempty"`
+	Backend      *string   `yaml:"backend,omitempty"`
+	Depth        int       `yaml:"depth,omitempty"`
+	SingleBranch bool      `yaml:"single_branch,omitempty"`
+	Filter       *string   `yaml:"filter,omitempty"`
+	Include      []*string `yaml:"include,omitempty"`
+	Exclude      []*string `yaml:"exclude,omitempty"`
+	Topics       []*string `yaml:"topics,omitempty"`
+	Visibility   *string   `yaml:"visibility,omitempty"`
+	Languages    []*string `yaml:"languages,omitempty"`
+	ActiveWithin *string   `yaml:"active_within,omitempty"`
+	Prune        *string   `yaml:"prune,omitempty"`
+}
+
+// RetryConfig - retry setion of config.yaml
+type RetryConfig struct {
+	Attempts   int       `yaml:"attempts,omitempty"`
+	Backoff    *string   `yaml:"backoff,omitempty"`
+	MaxBackoff *string   `yaml:"max_backoff,omitempty"`
+	RetryOn    []*string `yaml:"retry_on,omitempty"`
+}
+
+// HostLimitConfig - one host of limit setion of config.yaml
+type HostLimitConfig struct {
+	RPS    *float64 `yaml:"rps,omitempty"`
+	Clones *int     `yaml:"clones,omitempty"`
+}
+
+// LimitConfig - limit setion of config.yaml
+type LimitConfig struct {
+	Workers int                         `yaml:"workers,omitempty"`
+	RPS     float64                     `yaml:"rps,omitempty"`
+	Clones  int                         `yaml:"clones,omitempty"`
+	Hosts   map[string]*HostLimitConfig `yaml:"hosts,omitempty"`
 }
 
 // Config - config represent config.yaml
 type Config struct {
-	RepoConfig *RepoConfig `yaml:"repo"`
-	SyncConfig *SyncConfig `yaml:"sync"`
-	Cwd        *string     `yaml:"cwd"`
+	RepoConfigs RepoConfigs  `yaml:"repo"`
+	SyncConfig  *SyncConfig  `yaml:"sync"`
+	RetryConfig *RetryConfig `yaml:"retry,omitempty"`
+	LimitConfig *LimitConfig `yaml:"limit,omitempty"`
+	Cwd         *string      `yaml:"cwd"`
+}
+
+// SelectRemotes - Pick remotes by |name|, empty |name| means all of them
+func (c *Config) SelectRemotes(name string) ([]*RepoConfig, error) {
+	if len(c.RepoConfigs) == 0 {
+		return nil, fmt.Errorf("missing repo config")
+	}
+
+	// ). check name conflict when there are several remotes
+	if len(c.RepoConfigs) > 1 {
+		names := map[string]bool{}
+		for _, r := range c.RepoConfigs {
+			n := dd.Val(r.Name)
+			if len(n) == 0 {
+				return nil, fmt.Errorf("remote without name in a list of remotes")
+			}
+			if names[n] {
+				return nil, fmt.Errorf("duplicated remote name -> %s", n)
+			}
+			names[n] = true
+		}
+	}
+
+	if len(name) == 0 {
+		return c.RepoConfigs, nil
+	}
+	for _, r := range c.RepoConfigs {
+		if dd.Val(r.Name) == name {
+			return []*RepoConfig{r}, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot find remote -> %s", name)
+}
+
+// RemoteCwd - Working directory of remote |r|, named remote lives in its own subtree
+func (c *Config) RemoteCwd(r *RepoConfig) *string {
+	if r.Name == nil || len(dd.Val(r.Name)) == 0 {
+		return c.Cwd
+	}
+	return dd.Ptr(filepath.Join(dd.Val(c.Cwd), dd.Val(r.Name)))
 }
 
 // INIConfig - config represent gitup.ini
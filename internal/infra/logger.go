@@ -0,0 +1,56 @@
+package infra
+
+import (
+	"io"
+	"log"
+
+	"github.com/dannydd88/dd-go"
+)
+
+// newWriterLogger - Same as |dd.NewLevelLogger| but write all levels into |w|
+func newWriterLogger(level dd.LogLevel, w io.Writer) dd.LevelLogger {
+	flags := log.LstdFlags | log.Lmicroseconds | log.Lmsgprefix
+	return &writerLogger{
+		errorLogger: log.New(w, "E ", flags),
+		warnLogger:  log.New(w, "W ", flags),
+		infoLogger:  log.New(w, "I ", flags),
+		debugLogger: log.New(w, "D ", flags),
+		level:       level,
+	}
+}
+
+type writerLogger struct {
+	errorLogger *log.Logger
+	warnLogger  *log.Logger
+	infoLogger  *log.Logger
+	debugLogger *log.Logger
+	level       dd.LogLevel
+}
+
+func (l *writerLogger) Log(args ...any) {
+	l.Info(args...)
+}
+
+func (l *writerLogger) Error(args ...any) {
+	if l.level >= dd.ERROR {
+		l.errorLogger.Println(args...)
+	}
+}
+
+func (l *writerLogger) Warn(args ...any) {
+	if l.level >= dd.WARN {
+		l.warnLogger.Println(args...)
+	}
+}
+
+func (l *writerLogger) Info(args ...any) {
+	if l.level >= dd.INFO {
+		l.infoLogger.Println(args...)
+	}
+}
+
+func (l *writerLogger) Debug(args ...any) {
+	if l.level >= dd.DEBUG {
+		l.debugLogger.Println(args...)
+	}
+}
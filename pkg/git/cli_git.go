@@ -0,0 +1,316 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	cliTokenEnv  = "GITUP_TOKEN"
+	cliWaitDelay = 3 * time.Second
+
+	// credential helper reading token from env, so the token never shows up in process list
+	cliCredentialHelper = "!f() { echo username=dummy; echo \"password=$" + cliTokenEnv + "\"; }; f"
+)
+
+// CLIGit - a set of git commands via local cli git
+type CLIGit struct {
+	config *GitConfig
+	logger dd.LevelLogger
+}
+
+// NewCLIGit - Init a new Git instance via cli git
+func NewCLIGit(logger dd.LevelLogger, config *GitConfig) Git {
+	g := &CLIGit{
+		config: config,
+		logger: logger,
+	}
+	return g
+}
+
+// Path - current git repo path
+func (g *CLIGit) Path() *string {
+	return g.config.WorkDir
+}
+
+// Sync - Sync a git repository, clone if is a new one, update otherwise
+func (g *CLIGit) Sync(ctx context.Context) (bool, error) {
+	return syncWith(ctx, g.config.WorkDir, g.config.Bare, g.clone, g.fetch, g.pull)
+}
+
+func (g *CLIGit) clone(ctx context.Context) (bool, error) {
+	path := dd.Val(g.config.WorkDir)
+	g.logger.Debug("[cli-git]", "Clone repo ->", path)
+
+	args := append([]string{"clone"}, g.progressArgs()...)
+	if g.config.Bare {
+		args = append(args, "--bare")
+	}
+	if g.config.Depth > 0 {
+		args = append(args, fmt.Sprintf("--depth=%d", g.config.Depth))
+		// --depth implies --single-branch in cli git, keep it same as go-git
+		if !g.config.SingleBranch {
+			args = append(args, "--no-single-branch")
+		}
+	}
+	if g.config.SingleBranch {
+		args = append(args, "--single-branch")
+	}
+	if len(dd.Val(g.config.Filter)) != 0 {
+		// later fetches reuse the filter saved in remote config
+		args = append(args, "--filter="+dd.Val(g.config.Filter))
+	}
+	args = append(args, "--", g.url())
+
+	err := cloneInto(path, func(tmp string) error {
+		_, err := g.run(ctx, "", append(args, tmp)...)
+		return err
+	})
+	return err == nil, err
+}
+
+func (g *CLIGit) fetch(ctx context.Context) (bool, error) {
+	path := dd.Val(g.config.WorkDir)
+	g.logger.Debug("[cli-git]", "fetch repo ->", path)
+
+	if err := g.updateRemote(ctx); err != nil {
+		return false, err
+	}
+
+	before, err := g.run(ctx, path, "for-each-ref")
+	if err != nil {
+		return false, err
+	}
+	args := append(append([]string{"fetch"}, g.progressArgs()...), g.depthArgs()...)
+	args = append(append(args, "origin"), bareRefspecs(path, g.config.SingleBranch)...)
+	if _, err := g.run(ctx, path, args...); err != nil {
+		return false, err
+	}
+	after, err := g.run(ctx, path, "for-each-ref")
+	if err != nil {
+		return false, err
+	}
+	return before != after, nil
+}
+
+func (g *CLIGit) pull(ctx context.Context) (bool, error) {
+	path := dd.Val(g.config.WorkDir)
+	g.logger.Debug("[cli-git]", "pull repo ->", path)
+
+	if err := g.updateRemote(ctx); err != nil {
+		return false, err
+	}
+
+	before, err := g.run(ctx, path, "rev-parse", "HEAD")
+	if err != nil {
+		return false, err
+	}
+	args := append(append([]string{"pull", "--ff-only"}, g.progressArgs()...), g.depthArgs()...)
+	if _, err := g.run(ctx, path, args...); err != nil {
+		return false, err
+	}
+	after, err := g.run(ctx, path, "rev-parse", "HEAD")
+	if err != nil {
+		return false, err
+	}
+	return before != after, nil
+}
+
+// Status - Local state of the git repository
+func (g *CLIGit) Status(ctx context.Context) (*Status, error) {
+	path := dd.Val(g.config.WorkDir)
+	s, _, err := readStatus(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// ). bare repo has nothing but HEAD to tell
+	if s.Bare {
+		if head, err := g.run(ctx, path, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+			s.Head = shortHash(head)
+		}
+		return s, nil
+	}
+
+	// ). parse porcelain v2, see git-status(1)
+	out, err := g.run(ctx, path, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range strings.Split(out, "\n") {
+		fields := strings.Fields(l)
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "?":
+			s.Untracked++
+		case fields[0] == "1" || fields[0] == "2" || fields[0] == "u":
+			s.Changed++
+		case len(fields) < 3 || fields[0] != "#":
+		case fields[1] == "branch.oid" && fields[2] != "(initial)":
+			s.Head = shortHash(fields[2])
+		case fields[1] == "branch.upstream":
+			s.Upstream = fields[2]
+		case fields[1] == "branch.ab" && len(fields) == 4:
+			fmt.Sscanf(fields[2], "+%d", &s.Ahead)
+			fmt.Sscanf(fields[3], "-%d", &s.Behind)
+		}
+	}
+	return s, nil
+}
+
+// CreateBranch - Create branch |name| at revision |from| and check it out
+func (g *CLIGit) CreateBranch(ctx context.Context, name, from string) error {
+	path := dd.Val(g.config.WorkDir)
+	g.logger.Debug("[cli-git]", "Create branch ->", path, name, from)
+
+	_, err := g.run(ctx, path, "checkout", "--quiet", "--no-track", "-b", name, from, "--")
+	return err
+}
+
+// Checkout - Check out branch |name|, which is created from origin/|name| if only that one exists
+func (g *CLIGit) Checkout(ctx context.Context, name string) error {
+	path := dd.Val(g.config.WorkDir)
+	g.logger.Debug("[cli-git]", "Checkout branch ->", path, name)
+
+	// trailing "--" so that |name| is never taken as a file
+	_, err := g.run(ctx, path, "checkout", "--quiet", name, "--")
+	return err
+}
+
+// DeleteBranch - Delete branch |name| which is not checked out
+func (g *CLIGit) DeleteBranch(ctx context.Context, name string, force bool) error {
+	path := dd.Val(g.config.WorkDir)
+	g.logger.Debug("[cli-git]", "Delete branch ->", path, name)
+
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	_, err := g.run(ctx, path, "branch", flag, "--", name)
+	return err
+}
+
+func (g *CLIGit) isSSH() bool {
+	return strings.EqualFold(dd.Val(g.config.Transport), TransportSSH)
+}
+
+// url - url to clone from, ssh url is preferred in ssh mode
+func (g *CLIGit) url() string {
+	if g.isSSH() && len(dd.Val(g.config.SSHURL)) != 0 {
+		return dd.Val(g.config.SSHURL)
+	}
+	return dd.Val(g.config.URL)
+}
+
+// progressArgs - report progress only if someone is listening
+func (g *CLIGit) progressArgs() []string {
+	if g.config.Progress != nil {
+		return []string{"--progress"}
+	}
+	return []string{"--quiet"}
+}
+
+// depthArgs - keep updates as shallow as the clone
+func (g *CLIGit) depthArgs() []string {
+	if g.config.Depth > 0 {
+		return []string{fmt.Sprintf("--depth=%d", g.config.Depth)}
+	}
+	return nil
+}
+
+// updateRemote - point origin to ssh url in ssh mode, since the existing one may be cloned via http
+func (g *CLIGit) updateRemote(ctx context.Context) error {
+	if !g.isSSH() {
+		return nil
+	}
+	_, err := g.run(ctx, dd.Val(g.config.WorkDir), "remote", "set-url", "origin", g.url())
+	return err
+}
+
+// run - Run git with |args| in |dir|, return trimmed stdout, git is killed when |ctx| is done
+func (g *CLIGit) run(ctx context.Context, dir string, args ...string) (string, error) {
+	// ). prepare auth
+	var prefix []string
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if g.isSSH() {
+		env = append(env, "GIT_SSH_COMMAND="+sshCommand(g.config.SSH))
+	} else if len(dd.Val(g.config.Token)) != 0 {
+		// reset other helpers first, otherwise they take precedence
+		prefix = []string{"-c", "credential.helper=", "-c", "credential.helper=" + cliCredentialHelper}
+		env = append(env, cliTokenEnv+"="+dd.Val(g.config.Token))
+	}
+
+	// ). run command
+	cmd := exec.CommandContext(ctx, "git", append(prefix, args...)...)
+	// helpers spawned by git such as git-remote-https may hold the pipes after git is killed
+	cmd.WaitDelay = cliWaitDelay
+	cmd.Dir = dir
+	cmd.Env = env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if g.config.Progress != nil {
+		cmd.Stderr = io.MultiWriter(&stderr, g.config.Progress)
+	}
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("git %s: %w", args[0], ctx.Err())
+		}
+		msg := errorMessage(stderr.String())
+		if len(msg) == 0 {
+			return "", fmt.Errorf("git %s: %w", args[0], err)
+		}
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// sshCommand - build GIT_SSH_COMMAND from |config|,
+//
+//	passphrase is not supported here, use ssh-agent for encrypted keys, sync rejects a passphrase with cli backend
+func sshCommand(config *SSHConfig) string {
+	parts := []string{"ssh", "-o", "BatchMode=yes"}
+	if config == nil {
+		return strings.Join(parts, " ")
+	}
+	if len(dd.Val(config.PrivateKey)) != 0 {
+		parts = append(parts, "-o", "IdentitiesOnly=yes", "-i", shellQuote(expandHome(dd.Val(config.PrivateKey))))
+	}
+	if len(config.KnownHosts) != 0 {
+		var files []string
+		for _, f := range config.KnownHosts {
+			files = append(files, expandHome(dd.Val(f)))
+		}
+		parts = append(parts, "-o", shellQuote("UserKnownHostsFile="+strings.Join(files, " ")))
+	}
+	if len(dd.Val(config.User)) != 0 {
+		parts = append(parts, "-l", shellQuote(dd.Val(config.User)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// errorMessage - Error lines of git stderr, progress lines are dropped if there is any error line
+func errorMessage(stderr string) string {
+	var errs []string
+	for _, l := range strings.Split(stderr, "\n") {
+		if strings.HasPrefix(l, "fatal:") || strings.HasPrefix(l, "error:") {
+			errs = append(errs, l)
+		}
+	}
+	if len(errs) == 0 {
+		return strings.TrimSpace(stderr)
+	}
+	return strings.Join(errs, "\n")
+}
@@ -1,11 +1,73 @@
 package git
 
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	// TransportHTTP - clone and update via http url with token
+	TransportHTTP = "http"
+	// TransportSSH - clone and update via ssh url with key or ssh-agent
+	TransportSSH = "ssh"
+)
+
+const (
+	// BackendGoGit - git commands via go-git, the default one
+	BackendGoGit = "go-git"
+	// BackendCLI - git commands via installed git executable
+	BackendCLI = "cli"
+)
+
+const (
+	// ActionClone - repo does not exist locally and will be cloned
+	ActionClone = "clone"
+	// ActionFetch - bare repo exists and will be fetched
+	ActionFetch = "fetch"
+	// ActionPull - repo exists and will be pulled
+	ActionPull = "pull"
+	// ActionSkip - path is occupied by something else and cannot be synced
+	ActionSkip = "skip"
+
+	// clonePrefix - prefix of the temporary directory next to work dir while cloning
+	clonePrefix = ".gitup-clone-"
+)
+
+// SSHConfig - configs relative with ssh transport
+type SSHConfig struct {
+	// User - ssh user, "git" if not set
+	User *string
+	// PrivateKey - path of private key, use ssh-agent if not set
+	PrivateKey *string
+	// Passphrase - passphrase of |PrivateKey|
+	Passphrase *string
+	// KnownHosts - path of known_hosts files, use $SSH_KNOWN_HOSTS or ~/.ssh/known_hosts if not set
+	KnownHosts []*string
+}
+
 // GitConfig - configs relative with git
 type GitConfig struct {
-	URL     *string
-	WorkDir *string
-	Bare    bool
-	Token   *string
+	URL       *string
+	SSHURL    *string
+	WorkDir   *string
+	Bare      bool
+	Token     *string
+	Transport *string
+	SSH       *SSHConfig
+	// Depth - history depth of clone and update, 0 means full history
+	Depth int
+	// SingleBranch - only clone and update the default branch
+	SingleBranch bool
+	// Filter - partial clone filter such as "blob:none", only supported by cli backend
+	Filter *string
+	// Progress - receive sideband progress of clone and update, discarded if nil
+	Progress io.Writer
 }
 
 // Git - a set of git commands to one git repository and one local path
@@ -14,6 +76,155 @@ type Git interface {
 	Path() *string
 
 	// Sync - Sync a git repo, clone if is a new one, update otherwise
-	//       |bool| indicate that whether repo is updated
-	Sync() (bool, error)
+	//       |bool| indicate that whether repo is updated,
+	//       a cancelled clone leaves nothing behind
+	Sync(ctx context.Context) (bool, error)
+
+	// Status - Local state of the git repo without touching network,
+	//         bare or not is decided by what is on disk
+	Status(ctx context.Context) (*Status, error)
+
+	// CreateBranch - Create branch |name| at revision |from| and check it out,
+	//               it does not track |from|
+	CreateBranch(ctx context.Context, name, from string) error
+
+	// Checkout - Check out branch |name|, which is created from origin/|name| if only that one exists
+	Checkout(ctx context.Context, name string) error
+
+	// DeleteBranch - Delete branch |name| which is not checked out,
+	//               unless |force| it should be merged into HEAD or be the same as origin/|name|
+	DeleteBranch(ctx context.Context, name string, force bool) error
+}
+
+// NewGit - Init a new Git instance via |backend|, go-git if not set
+func NewGit(logger dd.LevelLogger, backend *string, config *GitConfig) Git {
+	if strings.EqualFold(dd.Val(backend), BackendCLI) {
+		return NewCLIGit(logger, config)
+	}
+	return NewGoGit(logger, config)
+}
+
+// SyncAction - Decide what |Sync| does to |workDir| without touching network,
+//
+//	|string| is the reason when action is |ActionSkip|
+func SyncAction(workDir *string, bare bool) (string, string) {
+	path := dd.Val(workDir)
+	var checkPath string
+	if bare {
+		checkPath = filepath.Join(path, "HEAD")
+	} else {
+		checkPath = filepath.Join(path, ".git", "HEAD")
+	}
+
+	// update if repository already existed
+	if dd.FileExists(dd.Ptr(checkPath)) {
+		gitDir := filepath.Dir(checkPath)
+		if !hasRefs(gitDir) && !hasObjects(gitDir) {
+			// left by an interrupted clone of older version, clone again unless there are other files
+			if entries, _ := os.ReadDir(path); !bare && len(entries) > 1 {
+				return ActionSkip, "incomplete clone with other files"
+			}
+			return ActionClone, "incomplete clone"
+		}
+		if bare {
+			return ActionFetch, ""
+		}
+		return ActionPull, ""
+	}
+
+	// else clone, but only into a missing or empty directory
+	entries, err := os.ReadDir(path)
+	if err == nil && len(entries) != 0 {
+		return ActionSkip, "path exists and is not a git repository"
+	} else if err != nil && !os.IsNotExist(err) {
+		return ActionSkip, err.Error()
+	}
+	return ActionClone, ""
+}
+
+// hasRefs - Whether git dir |gitDir| has any ref
+func hasRefs(gitDir string) bool {
+	if dd.FileExists(dd.Ptr(filepath.Join(gitDir, "packed-refs"))) {
+		return true
+	}
+	found := false
+	filepath.WalkDir(filepath.Join(gitDir, "refs"), func(_ string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+// hasObjects - Whether git dir |gitDir| has any loose or packed object
+func hasObjects(gitDir string) bool {
+	entries, err := os.ReadDir(filepath.Join(gitDir, "objects"))
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if e.Name() == "info" {
+			continue
+		}
+		if sub, err := os.ReadDir(filepath.Join(gitDir, "objects", e.Name())); err == nil && len(sub) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// syncWith - Dispatch |Sync| by |SyncAction| of |workDir|
+func syncWith(ctx context.Context, workDir *string, bare bool, clone, fetch, pull func(context.Context) (bool, error)) (bool, error) {
+	switch action, reason := SyncAction(workDir, bare); action {
+	case ActionFetch:
+		return fetch(ctx)
+	case ActionPull:
+		return pull(ctx)
+	case ActionClone:
+		return clone(ctx)
+	default:
+		return false, fmt.Errorf("skip %s: %s", dd.Val(workDir), reason)
+	}
+}
+
+// bareRefspecs - Refspecs updating branches and tags of bare clone at |gitDir| in place,
+// since a bare clone has no fetch refspec of its own in cli git, and one into refs/remotes in go-git,
+// only the branch of HEAD if |singleBranch|
+func bareRefspecs(gitDir string, singleBranch bool) []string {
+	if singleBranch {
+		if ref := readSymref(filepath.Join(gitDir, "HEAD")); len(ref) != 0 {
+			return []string{"+" + ref + ":" + ref}
+		}
+	}
+	return []string{"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"}
+}
+
+// cloneInto - Run |clone| into a temporary directory next to |workDir| and move it into place when done,
+//
+//	so that an interrupted or failed clone never leaves a half-initialized repo at |workDir|,
+//	|workDir| should be missing, empty or an incomplete clone
+func cloneInto(workDir string, clone func(tmp string) error) error {
+	// ). remove the leftover of a killed run
+	tmp := filepath.Join(filepath.Dir(workDir), clonePrefix+filepath.Base(workDir))
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(workDir), os.ModePerm); err != nil {
+		return err
+	}
+
+	// ). clone
+	if err := clone(tmp); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+
+	// ). move into place
+	if err := os.RemoveAll(workDir); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+	return os.Rename(tmp, workDir)
 }
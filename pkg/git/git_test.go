@@ -0,0 +1,231 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dannydd88/dd-go"
+	gg "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// gitCmd - Run cli git in |dir| with a fixed identity, fail |t| on error
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(
+		os.Environ(),
+		"GIT_AUTHOR_NAME=gitup",
+		"GIT_AUTHOR_EMAIL=gitup@example.com",
+		"GIT_COMMITTER_NAME=gitup",
+		"GIT_COMMITTER_EMAIL=gitup@example.com",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"HOME="+dir,
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %s\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// newUpstream - A local bare upstream with one commit on main and a feature branch,
+// return path of upstream and a work tree pushing to it
+func newUpstream(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	upstream := filepath.Join(root, "upstream.git")
+	work := filepath.Join(root, "work")
+	gitCmd(t, root, "init", "--quiet", "--bare", "--initial-branch=main", upstream)
+	gitCmd(t, root, "clone", "--quiet", upstream, work)
+	gitCmd(t, work, "commit", "--quiet", "--allow-empty", "-m", "first")
+	gitCmd(t, work, "push", "--quiet", "origin", "HEAD:main")
+	gitCmd(t, work, "push", "--quiet", "origin", "HEAD:feature")
+	return upstream, work
+}
+
+// pushCommit - Push a new commit to |branch| of upstream via |work|, return its hash
+func pushCommit(t *testing.T, work, branch string) string {
+	t.Helper()
+	gitCmd(t, work, "commit", "--quiet", "--allow-empty", "-m", "next "+branch)
+	gitCmd(t, work, "push", "--quiet", "origin", "HEAD:"+branch)
+	return gitCmd(t, work, "rev-parse", "HEAD")
+}
+
+func newTestGit(backend, url, workDir string, bare, singleBranch bool) Git {
+	return NewGit(dd.NewLevelLogger(dd.ERROR), dd.Ptr(backend), &GitConfig{
+		URL:          dd.Ptr(url),
+		WorkDir:      dd.Ptr(workDir),
+		Bare:         bare,
+		SingleBranch: singleBranch,
+	})
+}
+
+// forEachBackend - Run |test| with every backend, both should behave the same
+func forEachBackend(t *testing.T, test func(t *testing.T, backend string)) {
+	for _, backend := range []string{BackendGoGit, BackendCLI} {
+		t.Run(backend, func(t *testing.T) {
+			test(t, backend)
+		})
+	}
+}
+
+func TestSyncClonesAndPulls(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		upstream, work := newUpstream(t)
+		path := filepath.Join(t.TempDir(), "group", "repo")
+		g := newTestGit(backend, upstream, path, false, false)
+		ctx := context.Background()
+
+		// ). clone
+		if updated, err := g.Sync(ctx); err != nil || !updated {
+			t.Fatalf("clone: updated = %v, err = %v", updated, err)
+		}
+		if got := gitCmd(t, path, "rev-parse", "--abbrev-ref", "HEAD"); got != "main" {
+			t.Errorf("branch = %s, want main", got)
+		}
+
+		// ). nothing to pull
+		if updated, err := g.Sync(ctx); err != nil || updated {
+			t.Fatalf("pull without change: updated = %v, err = %v", updated, err)
+		}
+
+		// ). pull new commit
+		head := pushCommit(t, work, "main")
+		if updated, err := g.Sync(ctx); err != nil || !updated {
+			t.Fatalf("pull: updated = %v, err = %v", updated, err)
+		}
+		if got := gitCmd(t, path, "rev-parse", "HEAD"); got != head {
+			t.Errorf("head = %s, want %s", got, head)
+		}
+	})
+}
+
+func TestSyncFetchesBareClone(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		upstream, work := newUpstream(t)
+		path := filepath.Join(t.TempDir(), "repo.git")
+		g := newTestGit(backend, upstream, path, true, false)
+		ctx := context.Background()
+
+		// ). clone
+		if updated, err := g.Sync(ctx); err != nil || !updated {
+			t.Fatalf("clone: updated = %v, err = %v", updated, err)
+		}
+
+		// ). nothing to fetch
+		if updated, err := g.Sync(ctx); err != nil || updated {
+			t.Fatalf("fetch without change: updated = %v, err = %v", updated, err)
+		}
+
+		// ). branches and tags move in place
+		main := pushCommit(t, work, "main")
+		feature := pushCommit(t, work, "feature")
+		gitCmd(t, work, "tag", "v1")
+		gitCmd(t, work, "push", "--quiet", "origin", "v1")
+		if updated, err := g.Sync(ctx); err != nil || !updated {
+			t.Fatalf("fetch: updated = %v, err = %v", updated, err)
+		}
+		for ref, want := range map[string]string{
+			"refs/heads/main":    main,
+			"refs/heads/feature": feature,
+			"refs/tags/v1":       feature,
+		} {
+			if got := gitCmd(t, path, "rev-parse", ref); got != want {
+				t.Errorf("%s = %s, want %s", ref, got, want)
+			}
+		}
+	})
+}
+
+func TestSyncFetchesSingleBranchBareClone(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		upstream, work := newUpstream(t)
+		path := filepath.Join(t.TempDir(), "repo.git")
+		g := newTestGit(backend, upstream, path, true, true)
+		ctx := context.Background()
+
+		if _, err := g.Sync(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		// ). only the default branch is updated
+		main := pushCommit(t, work, "main")
+		pushCommit(t, work, "feature")
+		if updated, err := g.Sync(ctx); err != nil || !updated {
+			t.Fatalf("fetch: updated = %v, err = %v", updated, err)
+		}
+		if got := gitCmd(t, path, "rev-parse", "refs/heads/main"); got != main {
+			t.Errorf("main = %s, want %s", got, main)
+		}
+		if got := gitCmd(t, path, "for-each-ref", "refs/heads/feature"); len(got) != 0 {
+			t.Errorf("feature should not be fetched -> %s", got)
+		}
+	})
+}
+
+func TestSyncSkipsOccupiedPath(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		upstream, _ := newUpstream(t)
+		path := t.TempDir()
+		if err := os.WriteFile(filepath.Join(path, "file"), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		// ). never clone over files which are not a git repo
+		g := newTestGit(backend, upstream, path, false, false)
+		if _, err := g.Sync(context.Background()); err == nil || !strings.Contains(err.Error(), "skip") {
+			t.Errorf("err = %v, want skip", err)
+		}
+		if _, err := os.Stat(filepath.Join(path, "file")); err != nil {
+			t.Errorf("file is touched -> %v", err)
+		}
+	})
+}
+
+func TestAheadBehindMatchesRevList(t *testing.T) {
+	_, work := newUpstream(t)
+
+	// ). two branches forking from main, with a merge of main in between
+	gitCmd(t, work, "checkout", "--quiet", "-b", "local")
+	for i := 0; i < 3; i++ {
+		gitCmd(t, work, "commit", "--quiet", "--allow-empty", "-m", "local")
+	}
+	gitCmd(t, work, "checkout", "--quiet", "main")
+	for i := 0; i < 2; i++ {
+		gitCmd(t, work, "commit", "--quiet", "--allow-empty", "-m", "main")
+	}
+	gitCmd(t, work, "checkout", "--quiet", "local")
+	gitCmd(t, work, "merge", "--quiet", "--no-edit", "--no-ff", "main")
+	gitCmd(t, work, "commit", "--quiet", "--allow-empty", "-m", "local after merge")
+	gitCmd(t, work, "checkout", "--quiet", "main")
+	for i := 0; i < 4; i++ {
+		gitCmd(t, work, "commit", "--quiet", "--allow-empty", "-m", "main after merge")
+	}
+
+	r, err := gg.PlainOpen(work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pair := range [][2]string{{"local", "main"}, {"main", "local"}, {"main", "main"}, {"main~4", "local"}} {
+		local := plumbing.NewHash(gitCmd(t, work, "rev-parse", pair[0]))
+		upstream := plumbing.NewHash(gitCmd(t, work, "rev-parse", pair[1]))
+		ahead, behind, err := aheadBehind(context.Background(), r, local, upstream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := gitCmd(t, work, "rev-list", "--left-right", "--count", pair[0]+"..."+pair[1])
+		if got := fmt.Sprintf("%d\t%d", ahead, behind); got != want {
+			t.Errorf("%s...%s = %q, want %q", pair[0], pair[1], got, want)
+		}
+	}
+}
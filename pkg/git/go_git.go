@@ -1,27 +1,32 @@
 package git
 
 import (
+	"container/heap"
+	"context"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/dannydd88/dd-go"
 	gg "github.com/go-git/go-git/v5"
+	ggconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	gghttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	ggssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
 )
 
-// GoGit - a set of git commands via local cli git
+// GoGit - a set of git commands via go-git
 type GoGit struct {
 	config *GitConfig
 	logger dd.LevelLogger
 }
 
-// NewCLIGit - Init a new Git instance via cli git
+// NewGoGit - Init a new Git instance via go-git
 func NewGoGit(logger dd.LevelLogger, config *GitConfig) Git {
-	// make sure |path| is exist
-	if !dd.DirExists(config.WorkDir) {
-		os.MkdirAll(dd.Val(config.WorkDir), os.ModePerm)
-	}
 	g := &GoGit{
 		config: config,
 		logger: logger,
@@ -35,44 +40,56 @@ func (g *GoGit) Path() *string {
 }
 
 // Sync - Sync a git repository, clone if is a new one, update otherwise
-func (g *GoGit) Sync() (bool, error) {
-	var checkPath string
+func (g *GoGit) Sync(ctx context.Context) (bool, error) {
+	return syncWith(ctx, g.config.WorkDir, g.config.Bare, g.clone, g.fetch, g.pull)
+}
+
+func (g *GoGit) clone(ctx context.Context) (bool, error) {
 	path := dd.Val(g.config.WorkDir)
-	if g.config.Bare {
-		checkPath = filepath.Join(path, "HEAD")
-	} else {
-		checkPath = filepath.Join(path, ".git", "HEAD")
+	g.logger.Debug("[go-git]", "Clone repo ->", path)
+
+	auth, err := g.auth()
+	if err != nil {
+		return false, err
 	}
 
-	// update if repository already existed
-	if dd.FileExists(dd.Ptr(checkPath)) {
+	err = cloneInto(path, func(tmp string) error {
+		r, err := gg.PlainCloneContext(ctx, tmp, g.config.Bare, &gg.CloneOptions{
+			URL:          g.url(),
+			Progress:     g.progress(),
+			Auth:         auth,
+			Depth:        g.config.Depth,
+			SingleBranch: g.config.SingleBranch,
+		})
+		if err != nil {
+			return err
+		}
 		if g.config.Bare {
-			return g.fetch()
+			// go-git keeps other branches under refs/remotes, map them to heads as cli git does
+			err := r.FetchContext(ctx, &gg.FetchOptions{
+				RefSpecs: g.bareRefSpecs(tmp),
+				Auth:     auth,
+				Depth:    g.config.Depth,
+			})
+			if err != nil && err != gg.NoErrAlreadyUpToDate {
+				return err
+			}
 		} else {
-			return g.pull()
+			// record default branch of origin as cli git does, so that |Status| knows it
+			if head, err := r.Head(); err == nil && head.Name().IsBranch() {
+				r.Storer.SetReference(plumbing.NewSymbolicReference(
+					plumbing.NewRemoteHEADReferenceName("origin"),
+					plumbing.NewRemoteReferenceName("origin", head.Name().Short()),
+				))
+			}
 		}
-	}
-	// else clone
-	return g.clone()
-}
-
-func (g *GoGit) clone() (bool, error) {
-	path := dd.Val(g.config.WorkDir)
-	g.logger.Debug("[go-git]", "Clone repo ->", path)
-
-	_, err := gg.PlainClone(path, g.config.Bare, &gg.CloneOptions{
-		URL:      dd.Val(g.config.URL),
-		Progress: io.Discard,
-		Auth: &gghttp.BasicAuth{
-			Username: "dummy",
-			Password: dd.Val(g.config.Token),
-		},
+		return nil
 	})
 
 	return err == nil, err
 }
 
-func (g *GoGit) fetch() (bool, error) {
+func (g *GoGit) fetch(ctx context.Context) (bool, error) {
 	path := dd.Val(g.config.WorkDir)
 	g.logger.Debug("[go-git]", "fetch repo ->", path)
 
@@ -81,17 +98,28 @@ func (g *GoGit) fetch() (bool, error) {
 		return false, err
 	}
 
-	err = r.Fetch(&gg.FetchOptions{
-		Progress: io.Discard,
-		Auth: &gghttp.BasicAuth{
-			Username: "dummy",
-			Password: dd.Val(g.config.Token),
-		},
+	auth, err := g.auth()
+	if err != nil {
+		return false, err
+	}
+
+	err = r.FetchContext(ctx, &gg.FetchOptions{
+		RemoteURL: g.remoteURL(),
+		RefSpecs:  g.bareRefSpecs(path),
+		Progress:  g.progress(),
+		Auth:      auth,
+		Depth:     g.config.Depth,
 	})
-	return err == nil, err
+
+	if err == gg.NoErrAlreadyUpToDate {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-func (g *GoGit) pull() (bool, error) {
+func (g *GoGit) pull(ctx context.Context) (bool, error) {
 	path := dd.Val(g.config.WorkDir)
 	g.logger.Debug("[go-git]", "pull repo ->", path)
 
@@ -105,12 +133,17 @@ func (g *GoGit) pull() (bool, error) {
 		return false, err
 	}
 
-	err = w.Pull(&gg.PullOptions{
-		Progress: io.Discard,
-		Auth: &gghttp.BasicAuth{
-			Username: "dummy",
-			Password: dd.Val(g.config.Token),
-		},
+	auth, err := g.auth()
+	if err != nil {
+		return false, err
+	}
+
+	err = w.PullContext(ctx, &gg.PullOptions{
+		RemoteURL:    g.remoteURL(),
+		Progress:     g.progress(),
+		Auth:         auth,
+		Depth:        g.config.Depth,
+		SingleBranch: g.config.SingleBranch,
 	})
 
 	if err == gg.NoErrAlreadyUpToDate {
@@ -120,3 +153,463 @@ func (g *GoGit) pull() (bool, error) {
 	}
 	return true, nil
 }
+
+// Status - Local state of the git repository,
+//
+//	ignore rules of core.excludesFile are not applied when looking for untracked files
+func (g *GoGit) Status(ctx context.Context) (*Status, error) {
+	path := dd.Val(g.config.WorkDir)
+	s, _, err := readStatus(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := gg.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// ). resolve HEAD, nothing more to tell without any commit
+	head, err := r.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	s.Head = shortHash(head.Hash().String())
+	if s.Bare {
+		return s, nil
+	}
+
+	// ). count changes of worktree
+	w, err := r.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := w.Status()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range status {
+		if f.Worktree == gg.Untracked {
+			s.Untracked++
+		} else if f.Worktree != gg.Unmodified || f.Staging != gg.Unmodified {
+			s.Changed++
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// ). compare with upstream
+	if s.Detached() {
+		return s, nil
+	}
+	upstream := g.upstream(r, s.Branch)
+	if upstream == nil {
+		return s, nil
+	}
+	s.Upstream = upstream.Name().Short()
+	s.Ahead, s.Behind, err = aheadBehind(ctx, r, head.Hash(), upstream.Hash())
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// upstream - Remote tracking ref of |branch| from branch config, origin/|branch| if not configured,
+//
+//	nil if there is none
+func (g *GoGit) upstream(r *gg.Repository, branch string) *plumbing.Reference {
+	name := plumbing.NewRemoteReferenceName("origin", branch)
+	if c, err := r.Config(); err == nil {
+		if b, ok := c.Branches[branch]; ok && len(b.Remote) != 0 && b.Merge.IsBranch() {
+			name = plumbing.NewRemoteReferenceName(b.Remote, b.Merge.Short())
+		}
+	}
+	ref, err := r.Reference(name, true)
+	if err != nil {
+		return nil
+	}
+	return ref
+}
+
+// CreateBranch - Create branch |name| at revision |from| and check it out
+func (g *GoGit) CreateBranch(ctx context.Context, name, from string) error {
+	path := dd.Val(g.config.WorkDir)
+	g.logger.Debug("[go-git]", "Create branch ->", path, name, from)
+
+	r, w, err := g.openWorktree()
+	if err != nil {
+		return err
+	}
+
+	// ). check branch and start point
+	ref := plumbing.NewBranchReferenceName(name)
+	if _, err := r.Reference(ref, false); err == nil {
+		return fmt.Errorf("branch %s already exists", name)
+	}
+	h, err := r.ResolveRevision(plumbing.Revision(from))
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", from, err)
+	}
+
+	return w.Checkout(&gg.CheckoutOptions{
+		Hash:   *h,
+		Branch: ref,
+		Create: true,
+	})
+}
+
+// Checkout - Check out branch |name|, which is created from origin/|name| if only that one exists
+func (g *GoGit) Checkout(ctx context.Context, name string) error {
+	path := dd.Val(g.config.WorkDir)
+	g.logger.Debug("[go-git]", "Checkout branch ->", path, name)
+
+	r, w, err := g.openWorktree()
+	if err != nil {
+		return err
+	}
+
+	// ). local branch first
+	ref := plumbing.NewBranchReferenceName(name)
+	if _, err := r.Reference(ref, false); err == nil {
+		return w.Checkout(&gg.CheckoutOptions{Branch: ref})
+	}
+
+	// ). then create one tracking origin/|name| as cli git does
+	remote, err := r.Reference(plumbing.NewRemoteReferenceName("origin", name), true)
+	if err != nil {
+		return fmt.Errorf("branch %s not found", name)
+	}
+	err = w.Checkout(&gg.CheckoutOptions{
+		Hash:   remote.Hash(),
+		Branch: ref,
+		Create: true,
+	})
+	if err != nil {
+		return err
+	}
+	err = r.CreateBranch(&ggconfig.Branch{
+		Name:   name,
+		Remote: "origin",
+		Merge:  ref,
+	})
+	if err != nil && err != gg.ErrBranchExists {
+		return err
+	}
+	return nil
+}
+
+// DeleteBranch - Delete branch |name| which is not checked out
+func (g *GoGit) DeleteBranch(ctx context.Context, name string, force bool) error {
+	path := dd.Val(g.config.WorkDir)
+	g.logger.Debug("[go-git]", "Delete branch ->", path, name)
+
+	r, err := gg.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	// ). check branch
+	ref := plumbing.NewBranchReferenceName(name)
+	b, err := r.Reference(ref, false)
+	if err != nil {
+		return fmt.Errorf("branch %s not found", name)
+	}
+	head, err := r.Head()
+	if err != nil && err != plumbing.ErrReferenceNotFound {
+		return err
+	}
+	if head != nil && head.Name() == ref {
+		return fmt.Errorf("cannot delete branch %s checked out", name)
+	}
+
+	// ). unmerged work would be lost
+	if !force {
+		merged := false
+		if remote, err := r.Reference(plumbing.NewRemoteReferenceName("origin", name), true); err == nil {
+			merged = remote.Hash() == b.Hash()
+		}
+		if !merged && head != nil {
+			// merged if no commit of the branch is missing in HEAD
+			ahead, _, err := aheadBehind(ctx, r, b.Hash(), head.Hash())
+			if err != nil {
+				return err
+			}
+			merged = ahead == 0
+		}
+		if !merged {
+			return fmt.Errorf("branch %s is not fully merged", name)
+		}
+	}
+
+	// ). remove ref and its config
+	if err := r.Storer.RemoveReference(ref); err != nil {
+		return err
+	}
+	if err := r.DeleteBranch(name); err != nil && err != gg.ErrBranchNotFound {
+		return err
+	}
+	return nil
+}
+
+func (g *GoGit) bareRefSpecs(gitDir string) []ggconfig.RefSpec {
+	var result []ggconfig.RefSpec
+	for _, s := range bareRefspecs(gitDir, g.config.SingleBranch) {
+		result = append(result, ggconfig.RefSpec(s))
+	}
+	return result
+}
+
+func (g *GoGit) openWorktree() (*gg.Repository, *gg.Worktree, error) {
+	r, err := gg.PlainOpen(dd.Val(g.config.WorkDir))
+	if err != nil {
+		return nil, nil, err
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, w, nil
+}
+
+// progress - writer of sideband progress, never nil
+func (g *GoGit) progress() io.Writer {
+	if g.config.Progress == nil {
+		return io.Discard
+	}
+	return g.config.Progress
+}
+
+func (g *GoGit) isSSH() bool {
+	return strings.EqualFold(dd.Val(g.config.Transport), TransportSSH)
+}
+
+// url - url to clone from, ssh url is preferred in ssh mode
+func (g *GoGit) url() string {
+	if g.isSSH() && len(dd.Val(g.config.SSHURL)) != 0 {
+		return dd.Val(g.config.SSHURL)
+	}
+	return dd.Val(g.config.URL)
+}
+
+// remoteURL - url to override the remote of an existing repo, only in ssh mode
+//
+//	since the existing one may be cloned via http
+func (g *GoGit) remoteURL() string {
+	if g.isSSH() {
+		return g.url()
+	}
+	return ""
+}
+
+// auth - ssh key or agent auth in ssh mode, token based http auth otherwise,
+//
+//	nil if no token which means anonymous access
+func (g *GoGit) auth() (transport.AuthMethod, error) {
+	if g.isSSH() {
+		return newSSHAuth(g.config.SSH)
+	}
+	if len(dd.Val(g.config.Token)) == 0 {
+		return nil, nil
+	}
+	return &gghttp.BasicAuth{
+		Username: "dummy",
+		Password: dd.Val(g.config.Token),
+	}, nil
+}
+
+func newSSHAuth(config *SSHConfig) (transport.AuthMethod, error) {
+	if config == nil {
+		config = &SSHConfig{}
+	}
+	user := dd.ValD(config.User, ggssh.DefaultUsername)
+
+	// ). prepare host key callback from known_hosts
+	var knownHosts []string
+	for _, f := range config.KnownHosts {
+		knownHosts = append(knownHosts, expandHome(dd.Val(f)))
+	}
+	callback, err := ggssh.NewKnownHostsCallback(knownHosts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// ). use private key if provided
+	if len(dd.Val(config.PrivateKey)) != 0 {
+		auth, err := ggssh.NewPublicKeysFromFile(
+			user,
+			expandHome(dd.Val(config.PrivateKey)),
+			dd.Val(config.Passphrase),
+		)
+		if err != nil {
+			return nil, err
+		}
+		auth.HostKeyCallback = callback
+		return auth, nil
+	}
+
+	// ). fallback to ssh-agent
+	auth, err := ggssh.NewSSHAgentAuth(user)
+	if err != nil {
+		return nil, err
+	}
+	auth.HostKeyCallback = callback
+	return auth, nil
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// RemoteURL - Read the first url of remote |name| from the git repository at |path|
+func RemoteURL(path *string, name string) (*string, error) {
+	r, err := gg.PlainOpen(dd.Val(path))
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := r.Remote(name)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("remote %s has no url", name)
+	}
+	return dd.Ptr(urls[0]), nil
+}
+
+// BranchExists - Whether local branch |name| exists in the git repository at |path|
+func BranchExists(path *string, name string) bool {
+	r, err := gg.PlainOpen(dd.Val(path))
+	if err != nil {
+		return false
+	}
+	_, err = r.Reference(plumbing.NewBranchReferenceName(name), false)
+	return err == nil
+}
+
+// SetRemoteURL - Point remote |name| of the git repository at |path| to |url|
+func SetRemoteURL(path *string, name, url string) error {
+	r, err := gg.PlainOpen(dd.Val(path))
+	if err != nil {
+		return err
+	}
+
+	c, err := r.Config()
+	if err != nil {
+		return err
+	}
+
+	remote, ok := c.Remotes[name]
+	if !ok {
+		return fmt.Errorf("remote %s not found", name)
+	}
+	remote.URLs = []string{url}
+	return r.SetConfig(c)
+}
+
+const (
+	// sideLocal & sideUpstream - which tips of |aheadBehind| reach a commit
+	sideLocal    = 1
+	sideUpstream = 2
+	sideBoth     = sideLocal | sideUpstream
+)
+
+// aheadBehind - Commits reachable only from |local| and only from |upstream|,
+//
+//	walks both histories newest first as git merge-base does, and stops once every commit
+//	left in queue is reachable from both, so the shared history is never walked,
+//	parents beyond a shallow boundary are ignored
+func aheadBehind(ctx context.Context, r *gg.Repository, local, upstream plumbing.Hash) (int, int, error) {
+	if local == upstream {
+		return 0, 0, nil
+	}
+
+	// ). mark a commit reached from |side|, queue it again only if it is new to |side|,
+	//    |oneSided| counts queued entries of commits not yet reached from both sides
+	sides := map[plumbing.Hash]int{}
+	queued := map[plumbing.Hash]int{}
+	oneSided := 0
+	queue := &commitQueue{}
+	reach := func(h plumbing.Hash, side int) error {
+		if sides[h]&side == side {
+			return nil
+		}
+		c, err := r.CommitObject(h)
+		if err == plumbing.ErrObjectNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if sides[h] |= side; sides[h] == sideBoth {
+			oneSided -= queued[h]
+		} else {
+			oneSided++
+		}
+		queued[h]++
+		heap.Push(queue, c)
+		return nil
+	}
+	if err := reach(local, sideLocal); err != nil {
+		return 0, 0, err
+	}
+	if err := reach(upstream, sideUpstream); err != nil {
+		return 0, 0, err
+	}
+
+	// ). pass sides down to parents until histories meet
+	for oneSided != 0 {
+		if ctx.Err() != nil {
+			return 0, 0, ctx.Err()
+		}
+		c := heap.Pop(queue).(*object.Commit)
+		if queued[c.Hash]--; sides[c.Hash] != sideBoth {
+			oneSided--
+		}
+		for _, p := range c.ParentHashes {
+			if err := reach(p, sides[c.Hash]); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	ahead, behind := 0, 0
+	for _, side := range sides {
+		switch side {
+		case sideLocal:
+			ahead++
+		case sideUpstream:
+			behind++
+		}
+	}
+	return ahead, behind, nil
+}
+
+// commitQueue - |heap.Interface| of commits, newest committed first
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int { return len(q) }
+
+func (q commitQueue) Less(i, j int) bool { return q[i].Committer.When.After(q[j].Committer.When) }
+
+func (q commitQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *commitQueue) Push(x any) { *q = append(*q, x.(*object.Commit)) }
+
+func (q *commitQueue) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
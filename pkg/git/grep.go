@@ -0,0 +1,157 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+
+	"github.com/dannydd88/dd-go"
+	gg "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	// grepMaxLine - longer lines are skipped together with the rest of their file
+	grepMaxLine = 1024 * 1024
+	// grepBinaryProbe - a file with NUL in its first bytes is treated as binary and skipped
+	grepBinaryProbe = 8000
+)
+
+// GrepOptions - what to search by |Grep|
+type GrepOptions struct {
+	Pattern *regexp.Regexp
+	// Paths - only search files whose path or base name matches any of these globs, all if empty
+	Paths []string
+}
+
+// GrepMatch - one matched line
+type GrepMatch struct {
+	// Path - file path relative to repo root, slash separated
+	Path string
+	// Line - line number starting from 1
+	Line int
+	Text string
+}
+
+// Grep - Search tracked files in worktree of |workDir|, or files of HEAD if it is a bare repo,
+//
+//	binary files are skipped, it works the same for every backend
+func Grep(ctx context.Context, workDir *string, opts *GrepOptions) ([]*GrepMatch, error) {
+	dir := dd.Val(workDir)
+	r, err := gg.PlainOpen(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// ). bare repo has only committed files
+	if !dd.FileExists(dd.Ptr(filepath.Join(dir, ".git", "HEAD"))) {
+		return grepHead(ctx, r, opts)
+	}
+
+	// ). tracked files come from index, content from worktree
+	idx, err := r.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+	result := []*GrepMatch{}
+	for _, e := range idx.Entries {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if e.Mode == filemode.Submodule || e.Mode == filemode.Symlink || !MatchPath(opts.Paths, e.Name) {
+			continue
+		}
+		// deleted or replaced by a directory in worktree
+		f, err := os.Open(filepath.Join(dir, filepath.FromSlash(e.Name)))
+		if err != nil {
+			continue
+		}
+		result = append(result, grepReader(e.Name, f, opts.Pattern)...)
+		f.Close()
+	}
+	return result, nil
+}
+
+func grepHead(ctx context.Context, r *gg.Repository, opts *GrepOptions) ([]*GrepMatch, error) {
+	// ). nothing to search without any commit
+	result := []*GrepMatch{}
+	head, err := r.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		return result, nil
+	} else if err != nil {
+		return nil, err
+	}
+	c, err := r.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if (f.Mode != filemode.Regular && f.Mode != filemode.Executable) || !MatchPath(opts.Paths, f.Name) {
+			return nil
+		}
+		rd, err := f.Reader()
+		if err != nil {
+			return err
+		}
+		defer rd.Close()
+		result = append(result, grepReader(f.Name, rd, opts.Pattern)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// grepReader - Matched lines of file |name| read from |r|, nothing if it is binary
+func grepReader(name string, r io.Reader, pattern *regexp.Regexp) []*GrepMatch {
+	br := bufio.NewReader(r)
+	if probe, _ := br.Peek(grepBinaryProbe); bytes.IndexByte(probe, 0) >= 0 {
+		return nil
+	}
+
+	var result []*GrepMatch
+	s := bufio.NewScanner(br)
+	s.Buffer(nil, grepMaxLine)
+	for n := 1; s.Scan(); n++ {
+		if pattern.Match(s.Bytes()) {
+			result = append(result, &GrepMatch{
+				Path: name,
+				Line: n,
+				Text: s.Text(),
+			})
+		}
+	}
+	return result
+}
+
+// MatchPath - Whether file |name| matches any of |globs| by its path or base name, true if no glob
+func MatchPath(globs []string, name string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	for _, p := range globs {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, path.Base(name)); ok {
+			return true
+		}
+	}
+	return false
+}
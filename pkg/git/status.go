@@ -0,0 +1,111 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Status - local state of a git repo, collected without touching network
+type Status struct {
+	// Bare - repo has no worktree, so it is never dirty and has no upstream
+	Bare bool
+	// Branch - current branch, empty if HEAD is detached
+	Branch string
+	// Head - short hash of HEAD, empty if there is no commit yet
+	Head string
+	// DefaultBranch - branch which HEAD of origin points to, empty if unknown
+	DefaultBranch string
+	// Upstream - tracking branch of |Branch| such as origin/main, empty if none
+	Upstream string
+	// Ahead - commits of |Branch| not in |Upstream|
+	Ahead int
+	// Behind - commits of |Upstream| not in |Branch|
+	Behind int
+	// Changed - tracked files with staged or unstaged changes
+	Changed int
+	// Untracked - files neither tracked nor ignored
+	Untracked int
+	// Stashes - entries in stash
+	Stashes int
+}
+
+// Dirty - Whether worktree has any change or untracked file
+func (s *Status) Dirty() bool {
+	return s.Changed != 0 || s.Untracked != 0
+}
+
+// Detached - Whether HEAD points to a commit instead of a branch
+func (s *Status) Detached() bool {
+	return len(s.Branch) == 0
+}
+
+// OffDefault - Whether current branch is known to be other than the default one
+func (s *Status) OffDefault() bool {
+	return !s.Detached() && len(s.DefaultBranch) != 0 && s.Branch != s.DefaultBranch
+}
+
+// readStatus - Fill fields of |Status| which are plain files in git dir of |workDir|,
+//
+//	they are the same for every backend
+func readStatus(workDir string) (*Status, string, error) {
+	// ). locate git dir
+	s := &Status{}
+	gitDir := filepath.Join(workDir, ".git")
+	if _, err := os.Stat(filepath.Join(gitDir, "HEAD")); err != nil {
+		if _, err := os.Stat(filepath.Join(workDir, "HEAD")); err != nil {
+			return nil, "", fmt.Errorf("not a git repository: %s", workDir)
+		}
+		gitDir = workDir
+		s.Bare = true
+	}
+
+	// ). branches, a bare clone keeps HEAD of origin as its own
+	s.Branch = strings.TrimPrefix(readSymref(filepath.Join(gitDir, "HEAD")), "refs/heads/")
+	if s.Bare {
+		s.DefaultBranch = s.Branch
+	} else {
+		s.DefaultBranch = strings.TrimPrefix(readSymref(filepath.Join(gitDir, "refs", "remotes", "origin", "HEAD")), "refs/remotes/origin/")
+	}
+
+	// ). every stash entry is a line of its reflog
+	s.Stashes = countLines(filepath.Join(gitDir, "logs", "refs", "stash"))
+	return s, gitDir, nil
+}
+
+// readSymref - Target of symbolic ref file at |path|, empty if it is not a symbolic ref
+func readSymref(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(b)), "ref: ")
+	if !ok {
+		return ""
+	}
+	return ref
+}
+
+func countLines(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	n := 0
+	for s := bufio.NewScanner(f); s.Scan(); {
+		if len(strings.TrimSpace(s.Text())) != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
@@ -0,0 +1,102 @@
+package gitup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	bitbucketBaseURL = "https://%s/rest/api/1.0"
+
+	TagBitbucket = "[bitbucket]"
+)
+
+type BitbucketApi interface {
+	// Get - Send a GET request to bitbucket server api and decode json result into |out|,
+	//       retried on transient failures
+	Get(ctx context.Context, path string, query url.Values, out any) error
+
+	// Logger - Return the current logger for logging
+	Logger() dd.LevelLogger
+}
+
+// NewBitbucketApi
+// Helper function to create |BitbucketApi| for bitbucket server / data center,
+// |host| can be a hostname or a server root url such as "http://127.0.0.1:7990"
+func NewBitbucketApi(token, host *string, retry *RetryPolicy, limiter *HostLimiter, logger dd.LevelLogger) (BitbucketApi, error) {
+	// ). decide base url
+	h := strings.TrimSuffix(strings.TrimSpace(dd.Val(host)), "/")
+	if len(h) == 0 {
+		return nil, fmt.Errorf("%s missing host", TagBitbucket)
+	}
+	var base string
+	if strings.Contains(h, "://") {
+		base = h + "/rest/api/1.0"
+	} else {
+		base = fmt.Sprintf(bitbucketBaseURL, h)
+	}
+	if _, err := url.Parse(base); err != nil {
+		return nil, err
+	}
+
+	// ). prepare header
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	if token != nil && len(dd.Val(token)) != 0 {
+		header.Set("Authorization", "Bearer "+dd.Val(token))
+	}
+
+	api := &bitbucketContext{
+		client: newRestClient(base, header, retry, limiter),
+		logger: logger,
+	}
+
+	return api, nil
+}
+
+type bitbucketContext struct {
+	client *restClient
+	logger dd.LevelLogger
+}
+
+func (b *bitbucketContext) Get(ctx context.Context, path string, query url.Values, out any) error {
+	_, err := b.client.get(ctx, b.logger, path, query, out)
+	return err
+}
+
+func (b *bitbucketContext) Logger() dd.LevelLogger {
+	return b.logger
+}
+
+type BitbucketConfig struct {
+	Host           *string
+	Token          *string
+	FilterArchived bool
+	// Retry - retry policy of read only api requests, nil means no retry
+	Retry *RetryPolicy
+	// Limiter - rate limit of api requests, nil means unlimited
+	Limiter *HostLimiter
+	Logger  dd.LevelLogger
+}
+
+// NewBitbucketList
+// Helper function to create |RepoList| bitbucket server implement
+func NewBitbucketList(config *BitbucketConfig) (RepoList, error) {
+	// ). construct |BitbucketApi|
+	api, err := NewBitbucketApi(config.Token, config.Host, config.Retry, config.Limiter, config.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// ). construct
+	b := &bitbucketList{
+		BitbucketApi:   api,
+		filterArchived: config.FilterArchived,
+	}
+	return b, nil
+}
@@ -0,0 +1,154 @@
+package gitup
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dannydd88/dd-go"
+)
+
+type bitbucketLink struct {
+	Href string `json:"href"`
+	Name string `json:"name"`
+}
+
+type bitbucketProject struct {
+	Key string `json:"key"`
+}
+
+type bitbucketRepository struct {
+	ID       int              `json:"id"`
+	Slug     string           `json:"slug"`
+	Name     string           `json:"name"`
+	Archived bool             `json:"archived"`
+	Public   bool             `json:"public"`
+	Project  bitbucketProject `json:"project"`
+	Links    struct {
+		Clone []bitbucketLink `json:"clone"`
+	} `json:"links"`
+}
+
+type bitbucketPage struct {
+	Values        []*bitbucketRepository `json:"values"`
+	IsLastPage    bool                   `json:"isLastPage"`
+	NextPageStart int                    `json:"nextPageStart"`
+}
+
+type bitbucketList struct {
+	BitbucketApi
+	filterArchived bool
+}
+
+func (b *bitbucketList) Projects(ctx context.Context) ([]*Repo, error) {
+	// ). fetch all repositories visible to current user
+	return b.fetchRepositories(ctx, "repos")
+}
+
+func (b *bitbucketList) ProjectsByGroup(ctx context.Context, group *string) ([]*Repo, error) {
+	// ). project key is the only level of group, the rest is a repo slug prefix
+	key := dd.Val(group)
+	subSearch := false
+	if strings.Contains(key, "/") {
+		key = key[:strings.IndexByte(key, '/')]
+		subSearch = true
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("%s Empty group", TagBitbucket)
+	}
+
+	// ). fetch repositories of project
+	result, err := b.fetchRepositories(ctx, fmt.Sprintf("projects/%s/repos", url.PathEscape(key)))
+	if err != nil {
+		return nil, err
+	}
+
+	if subSearch {
+		// ). filter by prefix
+		subResult := []*Repo{}
+		for _, r := range result {
+			if strings.HasPrefix(r.FullPath, dd.Val(group)) {
+				subResult = append(subResult, r)
+			}
+		}
+		result = subResult
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%s Not find projects in %s", TagBitbucket, dd.Val(group))
+	}
+	return result, nil
+}
+
+func (b *bitbucketList) Project(ctx context.Context, group, name *string) (*Repo, error) {
+	// ). get repository directly
+	p := new(bitbucketRepository)
+	path := fmt.Sprintf("projects/%s/repos/%s", url.PathEscape(dd.Val(group)), url.PathEscape(dd.Val(name)))
+	if err := b.Get(ctx, path, nil, p); err != nil {
+		return nil, fmt.Errorf("%s Not find project[%s][%s] err[%s]", TagBitbucket, dd.Val(group), dd.Val(name), err)
+	}
+	return convertBitbucketRepo(p), nil
+}
+
+func (b *bitbucketList) fetchRepositories(ctx context.Context, path string) ([]*Repo, error) {
+	b.Logger().Info(
+		TagBitbucket,
+		"Waiting fetching repo...",
+		"[", "Path", "->", path, "]",
+	)
+
+	// ). walk through all pages
+	result := []*Repo{}
+	start := 0
+	for {
+		query := url.Values{}
+		query.Set("limit", strconv.Itoa(perPage))
+		query.Set("start", strconv.Itoa(start))
+
+		page := new(bitbucketPage)
+		if err := b.Get(ctx, path, query, page); err != nil {
+			return nil, err
+		}
+		for _, p := range page.Values {
+			if b.filterArchived && p.Archived {
+				continue
+			}
+			result = append(result, convertBitbucketRepo(p))
+		}
+
+		// Exit the loop when we've seen all pages.
+		if page.IsLastPage || page.NextPageStart <= start {
+			break
+		}
+		start = page.NextPageStart
+	}
+
+	b.Logger().Info(TagBitbucket, "Done...")
+	return result, nil
+}
+
+func convertBitbucketRepo(p *bitbucketRepository) *Repo {
+	r := &Repo{
+		ID:       p.ID,
+		Name:     p.Slug,
+		Group:    p.Project.Key,
+		FullPath: p.Project.Key + "/" + p.Slug,
+
+		// default branch needs one more request per repo, so it is left unknown
+		Visibility: "private",
+		Archived:   p.Archived,
+	}
+	if p.Public {
+		r.Visibility = "public"
+	}
+	for _, l := range p.Links.Clone {
+		switch strings.ToLower(l.Name) {
+		case "http", "https":
+			r.URL = l.Href
+		case "ssh":
+			r.SSHURL = l.Href
+		}
+	}
+	return r
+}
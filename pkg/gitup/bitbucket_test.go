@@ -0,0 +1,193 @@
+package gitup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dannydd88/dd-go"
+)
+
+// bitbucketStandIn - a bitbucket server api stand-in serving |repos| by request path in pages of |pageSize|,
+// paths missing in |repos| answer 404
+type bitbucketStandIn struct {
+	repos    map[string][]*bitbucketRepository
+	pageSize int
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func (s *bitbucketStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.Path+"?start="+r.URL.Query().Get("start")+"&limit="+r.URL.Query().Get("limit"))
+	s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/rest/api/1.0")
+	w.Header().Set("Content-Type", "application/json")
+
+	// ). a single repository
+	for _, repos := range s.repos {
+		for _, p := range repos {
+			if path == fmt.Sprintf("/projects/%s/repos/%s", p.Project.Key, p.Slug) {
+				json.NewEncoder(w).Encode(p)
+				return
+			}
+		}
+	}
+
+	repos, ok := s.repos[path]
+	if !ok {
+		http.Error(w, `{"errors":[{"message":"not found"}]}`, http.StatusNotFound)
+		return
+	}
+
+	// ). a page starting at |start|, ignoring |limit| as servers may cap it
+	start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+	end := min(start+s.pageSize, len(repos))
+	page := &bitbucketPage{
+		Values:        repos[start:end],
+		IsLastPage:    end == len(repos),
+		NextPageStart: end,
+	}
+	json.NewEncoder(w).Encode(page)
+}
+
+func bitbucketRepo(id int, key, slug string) *bitbucketRepository {
+	p := &bitbucketRepository{
+		ID:      id,
+		Slug:    slug,
+		Name:    strings.ToUpper(slug),
+		Project: bitbucketProject{Key: key},
+	}
+	p.Links.Clone = []bitbucketLink{
+		{Name: "ssh", Href: fmt.Sprintf("ssh://git@bitbucket.example:7999/%s/%s.git", strings.ToLower(key), slug)},
+		{Name: "http", Href: fmt.Sprintf("https://bitbucket.example/scm/%s/%s.git", strings.ToLower(key), slug)},
+	}
+	return p
+}
+
+func newTestBitbucketList(t *testing.T, host string, filterArchived bool) RepoList {
+	t.Helper()
+	l, err := NewBitbucketList(&BitbucketConfig{
+		Host:           dd.Ptr(host),
+		Token:          dd.Ptr("token"),
+		FilterArchived: filterArchived,
+		Logger:         dd.NewLevelLogger(dd.ERROR),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestBitbucketProjectsWalksPages(t *testing.T) {
+	archived := bitbucketRepo(3, "OPS", "old")
+	archived.Archived = true
+	standIn := &bitbucketStandIn{
+		repos: map[string][]*bitbucketRepository{
+			"/repos": {
+				bitbucketRepo(1, "INFRA", "a"),
+				bitbucketRepo(2, "INFRA", "b"),
+				archived,
+				bitbucketRepo(4, "OPS", "c"),
+				bitbucketRepo(5, "OPS", "d"),
+			},
+		},
+		pageSize: 2,
+	}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+
+	repos, err := newTestBitbucketList(t, srv.URL, true).Projects(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fullPaths(repos), "INFRA/a,INFRA/b,OPS/c,OPS/d"; got != want {
+		t.Errorf("repos = %s, want %s", got, want)
+	}
+
+	// ). each page starts where the last one says
+	want := strings.Join([]string{
+		fmt.Sprintf("/rest/api/1.0/repos?start=0&limit=%d", perPage),
+		fmt.Sprintf("/rest/api/1.0/repos?start=2&limit=%d", perPage),
+		fmt.Sprintf("/rest/api/1.0/repos?start=4&limit=%d", perPage),
+	}, ",")
+	if got := strings.Join(standIn.requests, ","); got != want {
+		t.Errorf("requests = %s, want %s", got, want)
+	}
+
+	// ). project key is the group, http clone link is the url
+	r := repos[0]
+	if r.Group != "INFRA" || r.Name != "a" {
+		t.Errorf("group = %s, name = %s, want INFRA, a", r.Group, r.Name)
+	}
+	if r.URL != "https://bitbucket.example/scm/infra/a.git" {
+		t.Errorf("url = %s", r.URL)
+	}
+	if r.SSHURL != "ssh://git@bitbucket.example:7999/infra/a.git" {
+		t.Errorf("ssh url = %s", r.SSHURL)
+	}
+}
+
+func TestBitbucketProjectsByGroup(t *testing.T) {
+	standIn := &bitbucketStandIn{
+		repos: map[string][]*bitbucketRepository{
+			"/projects/INFRA/repos": {
+				bitbucketRepo(1, "INFRA", "tool-a"),
+				bitbucketRepo(2, "INFRA", "web"),
+				bitbucketRepo(3, "INFRA", "tool-b"),
+			},
+		},
+		pageSize: 2,
+	}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+	l := newTestBitbucketList(t, srv.URL, false)
+
+	// ). the rest of group is a repo slug prefix
+	repos, err := l.ProjectsByGroup(context.Background(), dd.Ptr("INFRA/tool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fullPaths(repos), "INFRA/tool-a,INFRA/tool-b"; got != want {
+		t.Errorf("repos = %s, want %s", got, want)
+	}
+
+	// ). missing project fails
+	if _, err := l.ProjectsByGroup(context.Background(), dd.Ptr("NOPE")); err == nil {
+		t.Error("missing project is listed")
+	}
+}
+
+func TestBitbucketProject(t *testing.T) {
+	standIn := &bitbucketStandIn{
+		repos: map[string][]*bitbucketRepository{
+			"/projects/INFRA/repos": {bitbucketRepo(1, "INFRA", "a")},
+		},
+		pageSize: 2,
+	}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+	l := newTestBitbucketList(t, srv.URL, false)
+
+	r, err := l.Project(context.Background(), dd.Ptr("INFRA"), dd.Ptr("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.FullPath != "INFRA/a" || r.ID != 1 {
+		t.Errorf("repo = %s %d, want INFRA/a 1", r.FullPath, r.ID)
+	}
+
+	// ). missing repo names group and name
+	_, err = l.Project(context.Background(), dd.Ptr("INFRA"), dd.Ptr("missing"))
+	if err == nil || !strings.Contains(err.Error(), "[INFRA][missing]") || !strings.Contains(err.Error(), "404") {
+		t.Errorf("err = %v, want not find INFRA/missing", err)
+	}
+}
@@ -0,0 +1,180 @@
+package gitup
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dannydd88/gitup/pkg/git"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	TagBranch = "[branch]"
+
+	// BranchCreate - create a branch and check it out
+	BranchCreate = "create"
+	// BranchCheckout - check out an existing local or remote branch
+	BranchCheckout = "checkout"
+	// BranchDelete - delete a local branch
+	BranchDelete = "delete"
+)
+
+type BranchConfig struct {
+	Groups  []*string
+	Matcher *RepoMatcher
+	Backend *string
+	// Action - create | checkout | delete
+	Action string
+	// Name - branch to work on
+	Name string
+	// From - revision to create branch from, default branch of origin if empty
+	From string
+	// Force - delete branch even if it is not merged
+	Force bool
+}
+
+// Branch - create, check out or delete the same branch in local clones of repos listed by |Api| under |Cwd|,
+// bare clones are skipped, so are dirty worktrees when creating or checking out
+type Branch struct {
+	Api          RepoList
+	BranchConfig *BranchConfig
+	Cwd          *string
+	TaskRunner   dd.TaskRunner
+	Logger       dd.LevelLogger
+}
+
+// Go
+// Entrance of |branch|, return what happened to each repo in listed order,
+// |Skipped| of result tells why a repo is left untouched
+func (b *Branch) Go(ctx context.Context) []*RepoResult {
+	b.Logger.Info(TagBranch, "Started...")
+
+	// ). check action
+	switch b.BranchConfig.Action {
+	case BranchCreate, BranchCheckout, BranchDelete:
+	default:
+		return []*RepoResult{{
+			Action: b.BranchConfig.Action,
+			Err:    fmt.Errorf("unsupport branch action -> %s", b.BranchConfig.Action),
+		}}
+	}
+
+	// ). prepare repos
+	listing := listRepos(ctx, b.Api, b.BranchConfig.Groups, b.BranchConfig.Matcher, b.Logger, TagBranch)
+	results := append([]*RepoResult{}, listing.failures...)
+
+	// ). post branch task to runner
+	wg := new(sync.WaitGroup)
+	for _, repo := range listing.repos {
+		path := filepath.Join(dd.Val(b.Cwd), repo.FullPath)
+		result := &RepoResult{
+			Repo:   repo.FullPath,
+			Path:   path,
+			Action: b.BranchConfig.Action,
+		}
+		results = append(results, result)
+		if !IsGitRepo(path) {
+			result.Skipped = "not cloned"
+			continue
+		}
+		if ctx.Err() != nil {
+			result.Err = cancelledError(ctx)
+			continue
+		}
+
+		wg.Add(1)
+		g := git.NewGit(b.Logger, b.BranchConfig.Backend, &git.GitConfig{
+			WorkDir: dd.Ptr(path),
+		})
+		c := dd.Bind5(b.doBranch, ctx, g, repo, result, wg)
+		b.TaskRunner.Post(c)
+	}
+
+	b.Logger.Info(TagBranch, "Waiting branch repo...")
+	wg.Wait()
+	b.Logger.Info(TagBranch, "Done...")
+	return results
+}
+
+func (b *Branch) doBranch(
+	ctx context.Context,
+	g git.Git,
+	repo *Repo,
+	result *RepoResult,
+	wg *sync.WaitGroup,
+) error {
+	defer wg.Done()
+
+	// ). repo waiting in queue when cancelled is not started at all
+	if ctx.Err() != nil {
+		result.Err = cancelledError(ctx)
+		return result.Err
+	}
+	start := time.Now()
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+
+	// ). check whether the repo can be touched
+	status, err := g.Status(ctx)
+	if err != nil {
+		result.Err = err
+		return err
+	}
+	name := b.BranchConfig.Name
+	if skip := b.skipReason(status, g.Path(), name); len(skip) != 0 {
+		result.Skipped = skip
+		return nil
+	}
+
+	// ). do action
+	switch b.BranchConfig.Action {
+	case BranchCreate:
+		from := b.BranchConfig.From
+		if len(from) == 0 {
+			// default branch known by provider is more reliable than local origin/HEAD
+			def := status.DefaultBranch
+			if len(repo.DefaultBranch) != 0 {
+				def = repo.DefaultBranch
+			}
+			if len(def) == 0 {
+				err = fmt.Errorf("unknown default branch, set a start point")
+				break
+			}
+			from = "origin/" + def
+		}
+		err = g.CreateBranch(ctx, name, from)
+	case BranchCheckout:
+		err = g.Checkout(ctx, name)
+	case BranchDelete:
+		err = g.DeleteBranch(ctx, name, b.BranchConfig.Force)
+	}
+	result.Err = err
+	result.Updated = err == nil
+	return err
+}
+
+// skipReason - Why |status| of repo at |path| should not be touched, empty if it can be
+func (b *Branch) skipReason(status *git.Status, path *string, name string) string {
+	if status.Bare {
+		return "bare repo"
+	}
+	switch b.BranchConfig.Action {
+	case BranchCreate, BranchCheckout:
+		if status.Branch == name {
+			return "already on " + name
+		}
+		if status.Dirty() {
+			return "dirty worktree"
+		}
+	case BranchDelete:
+		if !git.BranchExists(path, name) {
+			return "no branch " + name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,208 @@
+package gitup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	TagExec = "[exec]"
+
+	// ActionExec - running command in a local clone
+	ActionExec = "exec"
+
+	execWaitDelay = 3 * time.Second
+)
+
+// errFailFast - error of repo which is not started since an earlier one failed
+var errFailFast = errors.New("not started: an earlier repo failed")
+
+type ExecConfig struct {
+	Groups  []*string
+	Matcher *RepoMatcher
+	// Command - program and its arguments to run in each repo
+	Command []string
+	// Ordered - print output of repos in listed order instead of finishing order
+	Ordered bool
+	// FailFast - start no more repo after the first failure, running ones are not interrupted
+	FailFast bool
+	// RepoTimeout - timeout of running in one repo, 0 means no timeout
+	RepoTimeout time.Duration
+}
+
+// Exec - run a command in each local clone of repos listed by |Api| under |Cwd|
+type Exec struct {
+	Api        RepoList
+	ExecConfig *ExecConfig
+	Cwd        *string
+	TaskRunner dd.TaskRunner
+	Logger     dd.LevelLogger
+	// Remote - name of remote set into results and prefixed to repos in output
+	Remote string
+	// Output - receive output of each repo as one block, discarded if nil
+	Output io.Writer
+}
+
+// execTask - one repo in |Exec|
+type execTask struct {
+	index   int
+	started bool
+	result  *RepoResult
+	output  bytes.Buffer
+}
+
+// Go
+// Entrance of |exec|, return what happened to each repo in listed order,
+// once |ctx| is done no more repo is started and running commands are killed
+func (e *Exec) Go(ctx context.Context) []*RepoResult {
+	e.Logger.Info(TagExec, "Started...")
+
+	// ). prepare repos
+	listing := listRepos(ctx, e.Api, e.ExecConfig.Groups, e.ExecConfig.Matcher, e.Logger, TagExec)
+	repos := listing.repos
+
+	// ). prepare context
+	waitCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan *execTask)
+	wg := new(sync.WaitGroup)
+	failed := new(atomic.Bool)
+
+	// ). post command task to runner
+	e.Logger.Info(TagExec, "Start running in repos ->", len(repos))
+	tasks := make([]*execTask, len(repos))
+	for i, repo := range repos {
+		tasks[i] = &execTask{
+			index: i,
+			result: &RepoResult{
+				Remote: e.Remote,
+				Repo:   repo.FullPath,
+				Path:   filepath.Join(dd.Val(e.Cwd), repo.FullPath),
+				Action: ActionExec,
+			},
+		}
+		wg.Add(1)
+		c := dd.Bind5(e.doExec, ctx, tasks[i], failed, done, wg)
+		e.TaskRunner.Post(c)
+	}
+
+	// ). async wait task done
+	go func() {
+		defer cancel()
+		e.Logger.Info(TagExec, "Waiting running repo...")
+		wg.Wait()
+	}()
+
+	// ). print & wait all task done, in ordered mode a repo waits for all repos before it
+	finished := make([]bool, len(tasks))
+	next := 0
+	for alive := true; alive; {
+		select {
+		case t := <-done:
+			if !e.ExecConfig.Ordered {
+				e.print(t)
+				continue
+			}
+			finished[t.index] = true
+			for ; next < len(tasks) && finished[next]; next++ {
+				e.print(tasks[next])
+			}
+		case <-waitCtx.Done():
+			e.Logger.Info(TagExec, "Done...")
+			alive = false
+		}
+	}
+
+	results := []*RepoResult{}
+	for _, f := range listing.failures {
+		f.Remote = e.Remote
+		results = append(results, f)
+	}
+	for _, t := range tasks {
+		results = append(results, t.result)
+	}
+	return results
+}
+
+func (e *Exec) doExec(
+	ctx context.Context,
+	t *execTask,
+	failed *atomic.Bool,
+	done chan *execTask,
+	wg *sync.WaitGroup,
+) error {
+	defer wg.Done()
+	defer func() {
+		done <- t
+	}()
+
+	// ). repo waiting in queue is not started once cancelled or failed fast
+	result := t.result
+	if ctx.Err() != nil {
+		result.Err = cancelledError(ctx)
+		return result.Err
+	}
+	if e.ExecConfig.FailFast && failed.Load() {
+		result.Err = errFailFast
+		return result.Err
+	}
+	if e.ExecConfig.RepoTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.ExecConfig.RepoTimeout)
+		defer cancel()
+	}
+
+	// ). run command with combined output
+	t.started = true
+	start := time.Now()
+	name := e.ExecConfig.Command[0]
+	cmd := exec.CommandContext(ctx, name, e.ExecConfig.Command[1:]...)
+	cmd.WaitDelay = execWaitDelay
+	cmd.Dir = result.Path
+	cmd.Env = append(os.Environ(), "GITUP_REPO="+result.Repo, "GITUP_PATH="+result.Path)
+	cmd.Stdout = &t.output
+	cmd.Stderr = &t.output
+	err := cmd.Run()
+	if err != nil && ctx.Err() != nil {
+		err = fmt.Errorf("%w: %w", err, ctx.Err())
+	}
+	result.Err = err
+	result.Duration = time.Since(start)
+	if err != nil {
+		failed.Store(true)
+	}
+	return err
+}
+
+// print - Write output of |t| with a header line, nothing for repo not started
+func (e *Exec) print(t *execTask) {
+	if e.Output == nil || !t.started {
+		return
+	}
+
+	repo := t.result.Repo
+	if len(t.result.Remote) != 0 {
+		repo = t.result.Remote + ":" + repo
+	}
+	state := "ok"
+	if t.result.Err != nil {
+		state = t.result.Err.Error()
+	}
+	fmt.Fprintf(e.Output, "==> %s [%s, %s]\n", repo, state, t.result.Duration.Round(time.Millisecond))
+	if out := t.output.Bytes(); len(out) != 0 {
+		e.Output.Write(out)
+		if out[len(out)-1] != '\n' {
+			fmt.Fprintln(e.Output)
+		}
+	}
+}
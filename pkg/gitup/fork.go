@@ -4,12 +4,16 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/dannydd88/dd-go"
 )
 
 const (
 	TagFork = "[fork]"
+
+	// ActionFork - action of |RepoResult| in |Fork|
+	ActionFork = "fork"
 )
 
 type ForkConfig struct {
@@ -26,6 +30,8 @@ type Fork struct {
 	ForkConfigs []*ForkConfig
 	TaskRunner  dd.TaskRunner
 	Logger      dd.LevelLogger
+	// Progress - live progress of forks, nil means plain log lines only
+	Progress Progress
 }
 
 type forkDetail struct {
@@ -38,15 +44,17 @@ type forkDetail struct {
 }
 
 // Go
-// Entrance of |fork|
-func (f *Fork) Go() {
+// Entrance of |fork|, return what happened to each source repo,
+// once |ctx| is done no more fork is started and requests of running ones are cancelled
+func (f *Fork) Go(ctx context.Context) []*RepoResult {
 	f.Logger.Info(TagFork, "Started...")
 
 	// ). prepare context
-	ctx, cancel := context.WithCancel(context.Background())
+	waitCtx, cancel := context.WithCancel(context.Background())
 	output := make(chan string)
 	defer close(output)
 	wg := new(sync.WaitGroup)
+	results := []*RepoResult{}
 
 	// ). do fork in each |ForkConfig|
 	for _, fc := range f.ForkConfigs {
@@ -58,15 +66,34 @@ func (f *Fork) Go() {
 				fc.FromGroup,
 				", skip this!",
 			)
+			results = append(results, &RepoResult{
+				Repo:   dd.Val(fc.FromGroup),
+				Action: ActionFork,
+				Err:    fmt.Errorf("len(to-repos) != len(from-repos)"),
+			})
 			continue
 		}
 
 		// ). foreach target repo
 		for i, r := range fc.FromRepos {
+			if ctx.Err() != nil {
+				results = append(results, &RepoResult{
+					Repo:   dd.Val(fc.FromGroup) + "/" + dd.Val(r),
+					Action: ActionFork,
+					Err:    cancelledError(ctx),
+				})
+				continue
+			}
+
 			// ). find target repo
-			repo, err := f.Api.Project(fc.FromGroup, r)
+			repo, err := f.Api.Project(ctx, fc.FromGroup, r)
 			if err != nil {
 				f.Logger.Warn(TagFork, "finding source repo meet error ->", err)
+				results = append(results, &RepoResult{
+					Repo:   dd.Val(fc.FromGroup) + "/" + dd.Val(r),
+					Action: ActionFork,
+					Err:    err,
+				})
 				continue
 			}
 
@@ -92,6 +119,11 @@ func (f *Fork) Go() {
 						detail.source.Name,
 						"] without new repo name, skip this",
 					)
+					results = append(results, &RepoResult{
+						Repo:   repo.FullPath,
+						Action: ActionFork,
+						Err:    fmt.Errorf("same group fork without new repo name"),
+					})
 					continue
 				}
 			}
@@ -100,8 +132,21 @@ func (f *Fork) Go() {
 			}
 			wg.Add(1)
 
+			// ). prepare result, path is where the forked repo lands
+			targetName := detail.targetName
+			if targetName == nil {
+				targetName = dd.Ptr(repo.Name)
+			}
+			result := &RepoResult{
+				Repo:   repo.FullPath,
+				Path:   dd.Val(detail.targetGroup) + "/" + dd.Val(targetName),
+				Action: ActionFork,
+			}
+			results = append(results, result)
+
 			// ). async do fork
-			c := dd.Bind4(doFork, f.Api, detail, output, wg)
+			f.progress().Start(1)
+			c := dd.Bind7(doFork, ctx, f.Api, f.progress(), detail, result, output, wg)
 			f.TaskRunner.Post(c)
 		}
 	}
@@ -118,36 +163,64 @@ func (f *Fork) Go() {
 		select {
 		case m := <-output:
 			f.Logger.Info(m)
-		case <-ctx.Done():
+		case <-waitCtx.Done():
 			f.Logger.Info(TagFork, "Done...")
 			alive = false
 		}
 	}
+
+	return results
 }
 
-func doFork(api RepoFork, detail *forkDetail, output chan string, wg *sync.WaitGroup) error {
+func doFork(
+	ctx context.Context,
+	api RepoFork,
+	progress Progress,
+	detail *forkDetail,
+	result *RepoResult,
+	output chan string,
+	wg *sync.WaitGroup,
+) error {
+	defer wg.Done()
+	defer func() {
+		progress.Finish(result.Repo, result.Err)
+	}()
+
+	// ). fork waiting in queue when cancelled is not started at all
+	if ctx.Err() != nil {
+		result.Err = cancelledError(ctx)
+		return result.Err
+	}
+	progress.Begin(result.Repo, result.Action)
+
+	start := time.Now()
+
 	// ). do fork
 	targetGroup := detail.targetGroup
 	if detail.sameGroupFork {
 		targetGroup = nil
 	}
-	forkedRepo, err := api.Fork(detail.source, targetGroup)
+	forkedRepo, err := api.Fork(ctx, detail.source, targetGroup)
 
 	// ). do rename if necessary
 	if err == nil && detail.changeNameFork {
-		_, err = api.Rename(forkedRepo, detail.targetName)
+		forkedRepo, err = api.Rename(ctx, forkedRepo, detail.targetName)
 	}
 
 	// ). do transfer if necessary
 	if err == nil && detail.sameGroupFork {
-		_, err = api.Transfer(forkedRepo, detail.targetGroup)
+		forkedRepo, err = api.Transfer(ctx, forkedRepo, detail.targetGroup)
 	}
 
 	// ). do remove fork relationship if necessary
 	if err == nil && detail.rmForkRelation {
-		_, err = api.DeleteForkRelationship(forkedRepo)
+		_, err = api.DeleteForkRelationship(ctx, forkedRepo)
 	}
 
+	result.Updated = err == nil
+	result.Err = err
+	result.Duration = time.Since(start)
+
 	var msg string
 	if err == nil {
 		msg = fmt.Sprintf(
@@ -169,7 +242,13 @@ func doFork(api RepoFork, detail *forkDetail, output chan string, wg *sync.WaitG
 	}
 
 	output <- msg
-	wg.Done()
 
 	return err
 }
+
+func (f *Fork) progress() Progress {
+	if f.Progress == nil {
+		return nopProgress{}
+	}
+	return f.Progress
+}
@@ -0,0 +1,103 @@
+package gitup
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	giteaBaseURL = "https://%s/api/v1"
+
+	TagGitea = "[gitea]"
+)
+
+// NewGiteaApi
+// Helper function to create |RestApi| of gitea, works with forgejo as well,
+// |host| can be a hostname or a server root url such as "http://127.0.0.1:3000"
+func NewGiteaApi(token, host *string, retry *RetryPolicy, limiter *HostLimiter, logger dd.LevelLogger) (RestApi, error) {
+	// ). decide base url
+	h := strings.TrimSuffix(strings.TrimSpace(dd.Val(host)), "/")
+	if len(h) == 0 {
+		return nil, fmt.Errorf("%s missing host", TagGitea)
+	}
+	var base string
+	if strings.Contains(h, "://") {
+		base = h + "/api/v1"
+	} else {
+		base = fmt.Sprintf(giteaBaseURL, h)
+	}
+	if _, err := url.Parse(base); err != nil {
+		return nil, err
+	}
+
+	// ). prepare header
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	if token != nil && len(dd.Val(token)) != 0 {
+		header.Set("Authorization", "token "+dd.Val(token))
+	}
+
+	return newRestApi(base, header, retry, limiter, logger), nil
+}
+
+type GiteaConfig struct {
+	Host           *string
+	Token          *string
+	FilterArchived bool
+	// Retry - retry policy of read only api requests, nil means no retry
+	Retry *RetryPolicy
+	// Limiter - rate limit of api requests, nil means unlimited
+	Limiter *HostLimiter
+	Logger  dd.LevelLogger
+}
+
+// NewGiteaList
+// Helper function to create |RepoList| gitea implement
+func NewGiteaList(config *GiteaConfig) (RepoList, error) {
+	// ). construct |RestApi|
+	api, err := NewGiteaApi(config.Token, config.Host, config.Retry, config.Limiter, config.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// ). construct
+	g := &giteaList{
+		restList: newGiteaRestList(api, config.FilterArchived),
+	}
+	return g, nil
+}
+
+// NewGiteaFork
+// Helper function to create |RepoFork| gitea implement
+func NewGiteaFork(config *GiteaConfig) (RepoFork, error) {
+	// ). construct |RestApi|
+	api, err := NewGiteaApi(config.Token, config.Host, config.Retry, config.Limiter, config.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// ). construct
+	g := &giteaFork{
+		giteaList: giteaList{
+			restList: newGiteaRestList(api, config.FilterArchived),
+		},
+	}
+	g.restFork.list = &g.restList
+
+	return g, nil
+}
+
+func newGiteaRestList(api RestApi, filterArchived bool) restList {
+	return restList{
+		RestApi:        api,
+		tag:            TagGitea,
+		filterArchived: filterArchived,
+		pageParam:      "limit",
+		pageSize:       giteaPerPage,
+		convert:        convertGiteaRepo,
+	}
+}
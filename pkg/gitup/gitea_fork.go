@@ -0,0 +1,7 @@
+package gitup
+
+// giteaFork - fork, rename and transfer are the same as other github like apis, see |restFork|
+type giteaFork struct {
+	giteaList
+	restFork
+}
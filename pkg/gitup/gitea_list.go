@@ -0,0 +1,48 @@
+package gitup
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+const (
+	giteaPerPage = 50
+)
+
+type giteaList struct {
+	restList
+}
+
+func (g *giteaList) Projects(ctx context.Context) ([]*Repo, error) {
+	// ). search all repositories visible to current user, search api wraps repositories in |data|
+	query := url.Values{}
+	if g.filterArchived {
+		query.Set("archived", "false")
+	}
+	return g.fetchRepositories(ctx, "repos/search", query, true)
+}
+
+func convertGiteaRepo(p *restRepository) *Repo {
+	r := &Repo{
+		ID:       p.ID,
+		URL:      p.CloneURL,
+		SSHURL:   p.SSHURL,
+		Name:     strings.TrimSpace(p.Name),
+		Group:    p.Owner.Login,
+		FullPath: p.FullName,
+
+		Topics:        knownTopics(p.Topics),
+		Visibility:    "public",
+		Language:      p.Language,
+		LastActivity:  p.UpdatedAt,
+		DefaultBranch: p.DefaultBranch,
+		Archived:      p.Archived,
+	}
+	if p.Private {
+		r.Visibility = "private"
+	} else if p.Internal {
+		r.Visibility = "internal"
+	}
+	return r
+}
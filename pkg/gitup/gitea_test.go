@@ -0,0 +1,120 @@
+package gitup
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dannydd88/dd-go"
+)
+
+func newTestGiteaList(t *testing.T, host string, filterArchived bool) RepoList {
+	t.Helper()
+	l, err := NewGiteaList(&GiteaConfig{
+		Host:           dd.Ptr(host),
+		Token:          dd.Ptr("token"),
+		FilterArchived: filterArchived,
+		Logger:         dd.NewLevelLogger(dd.ERROR),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestGiteaProjectsWalksSearchPages(t *testing.T) {
+	standIn := &restStandIn{
+		repos: map[string][][]*restRepository{
+			"/api/v1/repos/search": {
+				{githubRepo(1, "alice", "a"), githubRepo(2, "acme", "b")},
+				{githubRepo(3, "acme", "c")},
+			},
+		},
+		wrapped: map[string]bool{"/api/v1/repos/search": true},
+	}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+
+	repos, err := newTestGiteaList(t, srv.URL, true).Projects(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fullPaths(repos), "alice/a,acme/b,acme/c"; got != want {
+		t.Errorf("repos = %s, want %s", got, want)
+	}
+
+	// ). first request carries page size, token and archived filter on server side
+	first := standIn.requests[0]
+	if got := first.URL.Query().Get("limit"); got != fmt.Sprint(giteaPerPage) {
+		t.Errorf("limit = %s, want %d", got, giteaPerPage)
+	}
+	if got := first.URL.Query().Get("archived"); got != "false" {
+		t.Errorf("archived = %s, want false", got)
+	}
+	if got := first.Header.Get("Authorization"); got != "token token" {
+		t.Errorf("authorization = %s", got)
+	}
+	if got := len(standIn.paths()); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestGiteaProjectsByGroup(t *testing.T) {
+	standIn := &restStandIn{
+		repos: map[string][][]*restRepository{
+			"/api/v1/orgs/acme/repos": {
+				{githubRepo(1, "acme", "a")},
+				{githubRepo(2, "acme", "b")},
+			},
+			"/api/v1/users/alice/repos": {
+				{githubRepo(3, "alice", "tool-a"), githubRepo(4, "alice", "web")},
+			},
+		},
+	}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+	l := newTestGiteaList(t, srv.URL, false)
+
+	// ). org with pages
+	repos, err := l.ProjectsByGroup(context.Background(), dd.Ptr("acme"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fullPaths(repos), "acme/a,acme/b"; got != want {
+		t.Errorf("repos = %s, want %s", got, want)
+	}
+
+	// ). no such org, so alice is a user, and the rest of group is a repo prefix
+	repos, err = l.ProjectsByGroup(context.Background(), dd.Ptr("alice/tool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fullPaths(repos), "alice/tool-a"; got != want {
+		t.Errorf("repos = %s, want %s", got, want)
+	}
+	want := "/api/v1/orgs/acme/repos,/api/v1/orgs/acme/repos,/api/v1/orgs/alice/repos,/api/v1/users/alice/repos"
+	if got := strings.Join(standIn.paths(), ","); got != want {
+		t.Errorf("requests = %s, want %s", got, want)
+	}
+}
+
+func TestConvertGiteaRepoVisibility(t *testing.T) {
+	for _, c := range []struct {
+		private, internal bool
+		want              string
+	}{
+		{false, false, "public"},
+		{true, false, "private"},
+		{false, true, "internal"},
+		// private wins over internal
+		{true, true, "private"},
+	} {
+		p := githubRepo(1, "acme", "a")
+		p.Private, p.Internal = c.private, c.internal
+		if got := convertGiteaRepo(p).Visibility; got != c.want {
+			t.Errorf("private %v internal %v: visibility = %s, want %s", c.private, c.internal, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package gitup
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	githubHost       = "github.com"
+	githubBaseURL    = "https://api.github.com"
+	githubEnterprise = "https://%s/api/v3"
+
+	TagGithub = "[github]"
+)
+
+// NewGithubApi
+// Helper function to create |RestApi| of github,
+// |host| can be empty or "github.com" for github.com, a hostname for github enterprise,
+// or a full api base url such as "http://127.0.0.1:8080"
+func NewGithubApi(token, host *string, retry *RetryPolicy, limiter *HostLimiter, logger dd.LevelLogger) (RestApi, error) {
+	// ). decide base url
+	base := githubBaseURL
+	h := strings.TrimSpace(dd.Val(host))
+	switch {
+	case len(h) == 0 || h == githubHost || h == "api."+githubHost:
+	case strings.Contains(h, "://"):
+		base = h
+	default:
+		base = fmt.Sprintf(githubEnterprise, h)
+	}
+	if _, err := url.Parse(base); err != nil {
+		return nil, err
+	}
+
+	// ). prepare header
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if token != nil && len(dd.Val(token)) != 0 {
+		header.Set("Authorization", "Bearer "+dd.Val(token))
+	}
+
+	return newRestApi(base, header, retry, limiter, logger), nil
+}
+
+type GithubConfig struct {
+	Host           *string
+	Token          *string
+	FilterArchived bool
+	// Retry - retry policy of read only api requests, nil means no retry
+	Retry *RetryPolicy
+	// Limiter - rate limit of api requests, nil means unlimited
+	Limiter *HostLimiter
+	Logger  dd.LevelLogger
+}
+
+// NewGithubList
+// Helper function to create |RepoList| github implement
+func NewGithubList(config *GithubConfig) (RepoList, error) {
+	// ). construct |RestApi|
+	api, err := NewGithubApi(config.Token, config.Host, config.Retry, config.Limiter, config.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// ). construct
+	g := &githubList{
+		restList: newGithubRestList(api, config.FilterArchived),
+	}
+	return g, nil
+}
+
+// NewGithubFork
+// Helper function to create |RepoFork| github implement
+func NewGithubFork(config *GithubConfig) (RepoFork, error) {
+	// ). construct |RestApi|
+	api, err := NewGithubApi(config.Token, config.Host, config.Retry, config.Limiter, config.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// ). construct
+	g := &githubFork{
+		githubList: githubList{
+			restList: newGithubRestList(api, config.FilterArchived),
+		},
+	}
+	g.restFork.list = &g.restList
+
+	return g, nil
+}
+
+func newGithubRestList(api RestApi, filterArchived bool) restList {
+	return restList{
+		RestApi:        api,
+		tag:            TagGithub,
+		filterArchived: filterArchived,
+		pageParam:      "per_page",
+		pageSize:       perPage,
+		convert:        convertGithubRepo,
+	}
+}
@@ -0,0 +1,7 @@
+package gitup
+
+// githubFork - fork, rename and transfer are the same as other github like apis, see |restFork|
+type githubFork struct {
+	githubList
+	restFork
+}
@@ -0,0 +1,45 @@
+package gitup
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+type githubList struct {
+	restList
+}
+
+func (g *githubList) Projects(ctx context.Context) ([]*Repo, error) {
+	// ). fetch all repositories visible to current user
+	query := url.Values{}
+	query.Set("affiliation", "owner,collaborator,organization_member")
+	return g.fetchRepositories(ctx, "user/repos", query, false)
+}
+
+func convertGithubRepo(p *restRepository) *Repo {
+	r := &Repo{
+		ID:       p.ID,
+		URL:      p.CloneURL,
+		SSHURL:   p.SSHURL,
+		Name:     strings.TrimSpace(p.Name),
+		Group:    p.Owner.Login,
+		FullPath: p.FullName,
+
+		Topics:        knownTopics(p.Topics),
+		Visibility:    p.Visibility,
+		Language:      p.Language,
+		LastActivity:  p.PushedAt,
+		DefaultBranch: p.DefaultBranch,
+		Archived:      p.Archived,
+	}
+	// older github enterprise has no |visibility| field
+	if len(r.Visibility) == 0 {
+		if p.Private {
+			r.Visibility = "private"
+		} else {
+			r.Visibility = "public"
+		}
+	}
+	return r
+}
@@ -0,0 +1,395 @@
+package gitup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dannydd88/dd-go"
+)
+
+// restStandIn - a github like api stand-in serving |repos| by request path,
+// paths missing in |repos| answer 404, |status| overrides the answer of a path,
+// paths in |wrapped| answer repos wrapped in |data| like gitea search
+type restStandIn struct {
+	repos   map[string][][]*restRepository
+	status  map[string]int
+	wrapped map[string]bool
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func (s *restStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r)
+	s.mu.Unlock()
+
+	if code, ok := s.status[r.URL.Path]; ok {
+		http.Error(w, `{"message":"stand-in error"}`, code)
+		return
+	}
+	pages, ok := s.repos[r.URL.Path]
+	if !ok {
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+		return
+	}
+
+	// ). serve the page asked by |page| query, link to the next one like github and gitea
+	page := 1
+	fmt.Sscanf(r.URL.Query().Get("page"), "%d", &page)
+	if page < len(pages) {
+		next := *r.URL
+		next.Scheme, next.Host = "http", r.Host
+		if r.TLS != nil {
+			next.Scheme = "https"
+		}
+		q := next.Query()
+		q.Set("page", fmt.Sprint(page+1))
+		next.RawQuery = q.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next", <%s>; rel="last"`, next.String(), next.String()))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if s.wrapped[r.URL.Path] {
+		json.NewEncoder(w).Encode(&restSearchResult{OK: true, Data: pages[page-1]})
+		return
+	}
+	json.NewEncoder(w).Encode(pages[page-1])
+}
+
+func (s *restStandIn) paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []string
+	for _, r := range s.requests {
+		result = append(result, r.URL.Path)
+	}
+	return result
+}
+
+func githubRepo(id int, owner, name string) *restRepository {
+	return &restRepository{
+		ID:       id,
+		Name:     name,
+		FullName: owner + "/" + name,
+		CloneURL: "https://github.example/" + owner + "/" + name + ".git",
+		Owner:    restOwner{Login: owner},
+	}
+}
+
+func newTestGithubList(t *testing.T, host string, filterArchived bool) RepoList {
+	t.Helper()
+	l, err := NewGithubList(&GithubConfig{
+		Host:           dd.Ptr(host),
+		Token:          dd.Ptr("token"),
+		FilterArchived: filterArchived,
+		Logger:         dd.NewLevelLogger(dd.ERROR),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func fullPaths(repos []*Repo) string {
+	var paths []string
+	for _, r := range repos {
+		paths = append(paths, r.FullPath)
+	}
+	return strings.Join(paths, ",")
+}
+
+func TestGithubProjectsFollowsLinkHeader(t *testing.T) {
+	archived := githubRepo(3, "alice", "old")
+	archived.Archived = true
+	standIn := &restStandIn{
+		repos: map[string][][]*restRepository{
+			"/user/repos": {
+				{githubRepo(1, "alice", "a"), githubRepo(2, "acme", "b")},
+				{archived},
+				{githubRepo(4, "acme", "c")},
+			},
+		},
+	}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+
+	repos, err := newTestGithubList(t, srv.URL, true).Projects(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fullPaths(repos), "alice/a,acme/b,acme/c"; got != want {
+		t.Errorf("repos = %s, want %s", got, want)
+	}
+	if got := len(standIn.paths()); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+
+	// ). first request carries query and auth, later ones follow the link as is
+	first := standIn.requests[0]
+	if got := first.URL.Query().Get("per_page"); got != fmt.Sprint(perPage) {
+		t.Errorf("per_page = %s, want %d", got, perPage)
+	}
+	if got := first.URL.Query().Get("affiliation"); len(got) == 0 {
+		t.Error("affiliation is missing")
+	}
+	if got := first.Header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("authorization = %s", got)
+	}
+	if got := standIn.requests[2].URL.Query().Get("page"); got != "3" {
+		t.Errorf("last page = %s, want 3", got)
+	}
+}
+
+func TestGithubEnterpriseBaseURL(t *testing.T) {
+	standIn := &restStandIn{
+		repos: map[string][][]*restRepository{
+			"/api/v3/orgs/acme/repos": {{githubRepo(1, "acme", "a")}},
+		},
+	}
+	srv := httptest.NewTLSServer(standIn)
+	defer srv.Close()
+
+	// ). a bare hostname means github enterprise served under /api/v3 via https
+	l := newTestGithubList(t, srv.Listener.Addr().String(), false)
+	l.(*githubList).RestApi.(*restContext).client.client = srv.Client()
+
+	repos, err := l.ProjectsByGroup(context.Background(), dd.Ptr("acme"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fullPaths(repos), "acme/a"; got != want {
+		t.Errorf("repos = %s, want %s", got, want)
+	}
+}
+
+func TestGithubApiBaseURL(t *testing.T) {
+	for host, want := range map[string]string{
+		"":                      githubBaseURL,
+		"github.com":            githubBaseURL,
+		"api.github.com":        githubBaseURL,
+		"github.example":        "https://github.example/api/v3",
+		"http://127.0.0.1:8080": "http://127.0.0.1:8080",
+	} {
+		api, err := NewGithubApi(nil, dd.Ptr(host), nil, nil, dd.NewLevelLogger(dd.ERROR))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := api.(*restContext).client.baseURL; got != want {
+			t.Errorf("base url of %q = %s, want %s", host, got, want)
+		}
+	}
+}
+
+func TestGithubProjectsByGroupFallsBackToUser(t *testing.T) {
+	standIn := &restStandIn{
+		repos: map[string][][]*restRepository{
+			"/users/alice/repos": {
+				{githubRepo(1, "alice", "tool-a"), githubRepo(2, "alice", "web")},
+				{githubRepo(3, "alice", "tool-b")},
+			},
+		},
+	}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+
+	// ). no such org, so alice is a user, and the rest of group is a repo prefix
+	repos, err := newTestGithubList(t, srv.URL, false).ProjectsByGroup(context.Background(), dd.Ptr("alice/tool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fullPaths(repos), "alice/tool-a,alice/tool-b"; got != want {
+		t.Errorf("repos = %s, want %s", got, want)
+	}
+	if got, want := strings.Join(standIn.paths(), ","), "/orgs/alice/repos,/users/alice/repos,/users/alice/repos"; got != want {
+		t.Errorf("requests = %s, want %s", got, want)
+	}
+}
+
+func TestGithubProjectsByGroupKeepsOrgError(t *testing.T) {
+	for _, code := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError} {
+		standIn := &restStandIn{
+			repos: map[string][][]*restRepository{
+				"/users/acme/repos": {{githubRepo(1, "acme", "a")}},
+			},
+			status: map[string]int{
+				"/orgs/acme/repos": code,
+			},
+		}
+		srv := httptest.NewServer(standIn)
+
+		// ). only a missing org falls back to user
+		_, err := newTestGithubList(t, srv.URL, false).ProjectsByGroup(context.Background(), dd.Ptr("acme"))
+		if err == nil || !strings.Contains(err.Error(), fmt.Sprint(code)) {
+			t.Errorf("status %d: err = %v, want the org error", code, err)
+		}
+		if got, want := strings.Join(standIn.paths(), ","), "/orgs/acme/repos"; got != want {
+			t.Errorf("status %d: requests = %s, want %s", code, got, want)
+		}
+		srv.Close()
+	}
+}
+
+func TestConvertGithubRepo(t *testing.T) {
+	// ). github like providers always report topics, so missing ones mean no topic
+	r := convertGithubRepo(&restRepository{FullName: "acme/a", Language: "Go"})
+	if r.Topics == nil || len(r.Topics) != 0 {
+		t.Errorf("topics = %#v, want empty", r.Topics)
+	}
+	if r.Language != "Go" {
+		t.Errorf("language = %s, want Go", r.Language)
+	}
+}
+
+// goRunner - |dd.TaskRunner| running each task in its own goroutine
+type goRunner struct{}
+
+func (goRunner) Post(task dd.Task) error {
+	go task.Run()
+	return nil
+}
+
+func (goRunner) PostDelay(task dd.Task, delay time.Duration) error {
+	time.AfterFunc(delay, func() { task.Run() })
+	return nil
+}
+
+func TestGithubSameGroupForkRenamesAndTransfers(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		var repo *restRepository
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/acme/a":
+			repo = githubRepo(1, "acme", "a")
+		case "POST /repos/acme/a/forks":
+			// ). fork is created asynchronously
+			w.WriteHeader(http.StatusAccepted)
+			repo = githubRepo(2, "me", "a")
+		case "GET /repos/me/a":
+			if polls++; polls == 1 {
+				http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+				return
+			}
+			repo = githubRepo(2, "me", "a")
+		case "PATCH /repos/me/a":
+			repo = githubRepo(2, "me", "a-copy")
+		case "POST /repos/me/a-copy/transfer":
+			w.WriteHeader(http.StatusAccepted)
+			repo = githubRepo(2, "acme", "a-copy")
+		default:
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(repo)
+	}))
+	defer srv.Close()
+
+	api, err := NewGithubFork(&GithubConfig{
+		Host:   dd.Ptr(srv.URL),
+		Token:  dd.Ptr("token"),
+		Logger: dd.NewLevelLogger(dd.ERROR),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.(*githubFork).restFork.pollInterval = time.Millisecond
+
+	f := &Fork{
+		Api: api,
+		ForkConfigs: []*ForkConfig{{
+			FromGroup: dd.Ptr("acme"),
+			FromRepos: dd.PtrSlice([]string{"a"}),
+			ToRepos:   dd.PtrSlice([]string{"a-copy"}),
+		}},
+		TaskRunner: goRunner{},
+		Logger:     dd.NewLevelLogger(dd.ERROR),
+	}
+	results := f.Go(context.Background())
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("results = %+v", results[0])
+	}
+
+	// ). rename waits for the fork, transfer moves the renamed repo
+	want := strings.Join([]string{
+		"GET /repos/acme/a",
+		"POST /repos/acme/a/forks",
+		"GET /repos/me/a",
+		"GET /repos/me/a",
+		"PATCH /repos/me/a",
+		"POST /repos/me/a-copy/transfer",
+	}, ",")
+	if got := strings.Join(requests, ","); got != want {
+		t.Errorf("requests = %s, want %s", got, want)
+	}
+}
+
+func TestGithubRetriesReadsOnly(t *testing.T) {
+	standIn := &restStandIn{
+		repos: map[string][][]*restRepository{
+			"/orgs/acme/repos": {{githubRepo(1, "acme", "a")}},
+		},
+	}
+	failOnce := map[string]bool{"/orgs/acme/repos": true, "/repos/acme/a/forks": true}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		standIn.mu.Lock()
+		fail := failOnce[r.URL.Path]
+		failOnce[r.URL.Path] = false
+		standIn.mu.Unlock()
+		if fail {
+			standIn.mu.Lock()
+			standIn.requests = append(standIn.requests, r)
+			standIn.mu.Unlock()
+			http.Error(w, `{"message":"busy"}`, http.StatusServiceUnavailable)
+			return
+		}
+		standIn.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	retry, err := NewRetryPolicy(&RetryConfig{Backoff: dd.Ptr("1ms")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &GithubConfig{
+		Host:   dd.Ptr(srv.URL),
+		Token:  dd.Ptr("token"),
+		Retry:  retry,
+		Logger: dd.NewLevelLogger(dd.ERROR),
+	}
+
+	// ). listing is retried
+	l, err := NewGithubList(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos, err := l.ProjectsByGroup(context.Background(), dd.Ptr("acme"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fullPaths(repos), "acme/a"; got != want {
+		t.Errorf("repos = %s, want %s", got, want)
+	}
+
+	// ). fork is never retried
+	f, err := NewGithubFork(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Fork(context.Background(), repos[0], nil); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("fork err = %v, want 503", err)
+	}
+	if got, want := strings.Join(standIn.paths(), ","), "/orgs/acme/repos,/orgs/acme/repos,/repos/acme/a/forks"; got != want {
+		t.Errorf("requests = %s, want %s", got, want)
+	}
+}
@@ -21,12 +21,15 @@ type GitlabApi interface {
 	Logger() dd.LevelLogger
 }
 
-func NewGitlabApi(token, host *string, logger dd.LevelLogger) (GitlabApi, error) {
-	// ). construct gitlab client
-	c, err := gitlabapi.NewClient(
-		dd.Val(token),
-		gitlabapi.WithBaseURL(fmt.Sprintf(baseURL, dd.Val(host))),
-	)
+func NewGitlabApi(token, host *string, limiter *HostLimiter, logger dd.LevelLogger) (GitlabApi, error) {
+	// ). construct gitlab client, rate limit by |limiter| if any,
+	//    retries are left to |RetryPolicy| which knows requests unsafe to repeat
+	base := fmt.Sprintf(baseURL, dd.Val(host))
+	opts := []gitlabapi.ClientOptionFunc{gitlabapi.WithBaseURL(base), gitlabapi.WithoutRetries()}
+	if r := limiter.RateLimiter(hostOf(base)); r != nil {
+		opts = append(opts, gitlabapi.WithCustomLimiter(r))
+	}
+	c, err := gitlabapi.NewClient(dd.Val(token), opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -56,14 +59,18 @@ type GitlabConfig struct {
 	Host           *string
 	Token          *string
 	FilterArchived bool
-	Logger         dd.LevelLogger
+	// Retry - retry policy of api requests, nil means no retry
+	Retry *RetryPolicy
+	// Limiter - rate limit of api requests, nil means unlimited
+	Limiter *HostLimiter
+	Logger  dd.LevelLogger
 }
 
 // NewGitlabList
 // Helper function to create |RepoList| gitlab implement
 func NewGitlabList(config *GitlabConfig) (RepoList, error) {
 	// ). construct |GitlabApi|
-	api, err := NewGitlabApi(config.Token, config.Host, config.Logger)
+	api, err := NewGitlabApi(config.Token, config.Host, config.Limiter, config.Logger)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +79,7 @@ func NewGitlabList(config *GitlabConfig) (RepoList, error) {
 	g := &gitlabList{
 		GitlabApi:      api,
 		filterArchived: config.FilterArchived,
+		retry:          config.Retry,
 	}
 	return g, nil
 }
@@ -80,7 +88,7 @@ func NewGitlabList(config *GitlabConfig) (RepoList, error) {
 // Helper function to create |RepoFork| gitlab implement
 func NewGitlabFork(config *GitlabConfig) (RepoFork, error) {
 	// ). construct |GitlabApi|
-	api, err := NewGitlabApi(config.Token, config.Host, config.Logger)
+	api, err := NewGitlabApi(config.Token, config.Host, config.Limiter, config.Logger)
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +98,28 @@ func NewGitlabFork(config *GitlabConfig) (RepoFork, error) {
 		gitlabList: gitlabList{
 			GitlabApi:      api,
 			filterArchived: config.FilterArchived,
+			retry:          config.Retry,
+		},
+	}
+
+	return g, nil
+}
+
+// NewGitlabSearch
+// Helper function to create |RepoSearch| gitlab implement
+func NewGitlabSearch(config *GitlabConfig) (RepoSearch, error) {
+	// ). construct |GitlabApi|
+	api, err := NewGitlabApi(config.Token, config.Host, config.Limiter, config.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// ). construct
+	g := &gitlabSearch{
+		gitlabList: gitlabList{
+			GitlabApi:      api,
+			filterArchived: config.FilterArchived,
+			retry:          config.Retry,
 		},
 	}
 
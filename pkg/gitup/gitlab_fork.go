@@ -1,6 +1,9 @@
 package gitup
 
 import (
+	"context"
+	"fmt"
+
 	gitlabapi "gitlab.com/gitlab-org/api/client-go"
 )
 
@@ -8,14 +11,20 @@ type gitlabFork struct {
 	gitlabList
 }
 
-func (g *gitlabFork) Fork(r *Repo, group *string) (*Repo, error) {
+func (g *gitlabFork) Fork(ctx context.Context, r *Repo, group *string) (*Repo, error) {
 	// ). prepare fork options
 	opt := &gitlabapi.ForkProjectOptions{
 		NamespacePath: group,
 	}
 
-	// ). do fork
-	p, resp, err := g.Api().Projects.ForkProject(r.ID, opt)
+	// ). do fork, never retried since a fork applied before a lost response would be forked twice
+	var p *gitlabapi.Project
+	var resp *gitlabapi.Response
+	err := g.once(ctx, func(ctx context.Context) error {
+		var err error
+		p, resp, err = g.Api().Projects.ForkProject(r.ID, opt, gitlabapi.WithContext(ctx))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +41,12 @@ func (g *gitlabFork) Fork(r *Repo, group *string) (*Repo, error) {
 		opt := &gitlabapi.PatchProjectJobTokenAccessSettingsOptions{
 			Enabled: false,
 		}
-		resp, err := g.Api().JobTokenScope.PatchProjectJobTokenAccessSettings(p.ID, opt)
+		var resp *gitlabapi.Response
+		err := g.call(ctx, fmt.Sprintf("disable job token access %d", p.ID), func(ctx context.Context) error {
+			var err error
+			resp, err = g.Api().JobTokenScope.PatchProjectJobTokenAccessSettings(p.ID, opt, gitlabapi.WithContext(ctx))
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -48,19 +62,26 @@ func (g *gitlabFork) Fork(r *Repo, group *string) (*Repo, error) {
 		Name:     p.Name,
 		Group:    p.Namespace.FullPath,
 		URL:      p.HTTPURLToRepo,
+		SSHURL:   p.SSHURLToRepo,
 		FullPath: p.PathWithNamespace,
 	}, nil
 }
 
-func (g *gitlabFork) Rename(r *Repo, name *string) (*Repo, error) {
+func (g *gitlabFork) Rename(ctx context.Context, r *Repo, name *string) (*Repo, error) {
 	// ). prepare edit project options
 	opt := &gitlabapi.EditProjectOptions{
 		Name: name,
 		Path: name,
 	}
 
-	// ). do rename
-	p, resp, err := g.Api().Projects.EditProject(r.ID, opt)
+	// ). do rename, not retried since server may have applied a try which looks failed
+	var p *gitlabapi.Project
+	var resp *gitlabapi.Response
+	err := g.once(ctx, func(ctx context.Context) error {
+		var err error
+		p, resp, err = g.Api().Projects.EditProject(r.ID, opt, gitlabapi.WithContext(ctx))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -79,18 +100,25 @@ func (g *gitlabFork) Rename(r *Repo, name *string) (*Repo, error) {
 		Name:     p.Name,
 		Group:    p.Namespace.FullPath,
 		URL:      p.HTTPURLToRepo,
+		SSHURL:   p.SSHURLToRepo,
 		FullPath: p.PathWithNamespace,
 	}, nil
 }
 
-func (g *gitlabFork) Transfer(r *Repo, group *string) (*Repo, error) {
+func (g *gitlabFork) Transfer(ctx context.Context, r *Repo, group *string) (*Repo, error) {
 	// ). prepare transfer options
 	opt := &gitlabapi.TransferProjectOptions{
 		Namespace: group,
 	}
 
-	// ). do transfer
-	p, resp, err := g.Api().Projects.TransferProject(r.ID, opt)
+	// ). do transfer, not retried since a transferred project fails to transfer again
+	var p *gitlabapi.Project
+	var resp *gitlabapi.Response
+	err := g.once(ctx, func(ctx context.Context) error {
+		var err error
+		p, resp, err = g.Api().Projects.TransferProject(r.ID, opt, gitlabapi.WithContext(ctx))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -109,13 +137,19 @@ func (g *gitlabFork) Transfer(r *Repo, group *string) (*Repo, error) {
 		Name:     p.Name,
 		Group:    p.Namespace.FullPath,
 		URL:      p.HTTPURLToRepo,
+		SSHURL:   p.SSHURLToRepo,
 		FullPath: p.PathWithNamespace,
 	}, nil
 }
 
-func (g *gitlabFork) DeleteForkRelationship(r *Repo) (bool, error) {
-	// ). do delete fork relationship
-	resp, err := g.Api().Projects.DeleteProjectForkRelation(r.ID)
+func (g *gitlabFork) DeleteForkRelationship(ctx context.Context, r *Repo) (bool, error) {
+	// ). do delete fork relationship, not retried since a deleted relationship fails to delete again
+	var resp *gitlabapi.Response
+	err := g.once(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = g.Api().Projects.DeleteProjectForkRelation(r.ID, gitlabapi.WithContext(ctx))
+		return err
+	})
 	if err != nil {
 		return false, err
 	}
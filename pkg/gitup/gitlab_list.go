@@ -12,33 +12,35 @@ import (
 
 const (
 	perPage = 100
+
+	// gitlabTimeout - timeout of one api request, retries and waits between pages are not counted
+	gitlabTimeout = 1 * time.Minute
 )
 
 type gitlabList struct {
 	GitlabApi
 	filterArchived bool
+	retry          *RetryPolicy
 }
 
-func (g *gitlabList) Projects() []*Repo {
-	// ). prepare result
-	result := []*Repo{}
-
+func (g *gitlabList) Projects(ctx context.Context) ([]*Repo, error) {
 	// ). fetch all projects
-	projects, err := g.fetchProjects(nil)
+	projects, err := g.fetchProjects(ctx, nil)
 	if err != nil {
-		return result
+		return nil, err
 	}
 
 	// ). build final result
+	result := []*Repo{}
 	for _, v := range dd.Val(projects) {
 		result = append(result, v...)
 	}
-	return result
+	return result, nil
 }
 
-func (g *gitlabList) ProjectsByGroup(group *string) ([]*Repo, error) {
+func (g *gitlabList) ProjectsByGroup(ctx context.Context, group *string) ([]*Repo, error) {
 	// ). fetch projects by |group| string
-	projects, err := g.fetchProjects(group)
+	projects, err := g.fetchProjects(ctx, group)
 	if err != nil {
 		return nil, err
 	}
@@ -72,9 +74,9 @@ func (g *gitlabList) ProjectsByGroup(group *string) ([]*Repo, error) {
 	return result, nil
 }
 
-func (g *gitlabList) Project(group, name *string) (*Repo, error) {
+func (g *gitlabList) Project(ctx context.Context, group, name *string) (*Repo, error) {
 	// ). list projects with group
-	repos, err := g.ProjectsByGroup(group)
+	repos, err := g.ProjectsByGroup(ctx, group)
 	if err != nil {
 		return nil, err
 	}
@@ -89,14 +91,14 @@ func (g *gitlabList) Project(group, name *string) (*Repo, error) {
 	return nil, fmt.Errorf("[gitlab] Not find project[%s][%s]", dd.Val(group), dd.Val(name))
 }
 
-func (g *gitlabList) fetchProjects(group *string) (*map[string][]*Repo, error) {
-	// ). init context & channel
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+func (g *gitlabList) fetchProjects(ctx context.Context, group *string) (*map[string][]*Repo, error) {
+	// ). init channel
 	dst := make(chan []*gitlabapi.Project, 1)
-	defer close(dst)
+	finished := make(chan struct{})
+	var listErr error
 
 	go func() {
-		defer cancel()
+		defer close(finished)
 
 		// Prepare list project options
 		opt := &gitlabapi.ListProjectsOptions{
@@ -104,7 +106,6 @@ func (g *gitlabList) fetchProjects(group *string) (*map[string][]*Repo, error) {
 				Page:    1,
 				PerPage: perPage,
 			},
-			Simple: dd.Ptr(true),
 		}
 		if g.filterArchived {
 			opt.Archived = dd.Ptr(false)
@@ -116,9 +117,15 @@ func (g *gitlabList) fetchProjects(group *string) (*map[string][]*Repo, error) {
 
 		for {
 			// Get the first page with projects.
-			ps, resp, err := g.Api().Projects.ListProjects(opt)
+			var ps []*gitlabapi.Project
+			var resp *gitlabapi.Response
+			err := g.call(ctx, fmt.Sprintf("list projects page %d", opt.Page), func(ctx context.Context) error {
+				var err error
+				ps, resp, err = g.Api().Projects.ListProjects(opt, gitlabapi.WithContext(ctx))
+				return err
+			})
 			if err != nil {
-				g.Logger().Info(TagGitlab, "List projects error", err)
+				listErr = err
 				return
 			}
 
@@ -146,24 +153,59 @@ func (g *gitlabList) fetchProjects(group *string) (*map[string][]*Repo, error) {
 		case ps := <-dst:
 			convertToRepo(dd.Ptr(output), ps)
 
-		case <-ctx.Done():
+		case <-finished:
+			// the last page may still be buffered
+			select {
+			case ps := <-dst:
+				convertToRepo(dd.Ptr(output), ps)
+			default:
+			}
 			g.Logger().Info(TagGitlab, "Done...")
 			alive = false
 		}
 	}
 
+	if listErr != nil {
+		return nil, listErr
+	}
 	return dd.Ptr(output), nil
 }
 
+// call - Run one api request with retry policy, |name| is used for logging,
+// each try gets its own timeout, no more retry once |ctx| is done,
+// only for requests safe to repeat since a failed try may have been applied by server
+func (g *gitlabList) call(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	_, err := g.retry.Do(ctx, g.Logger(), TagGitlab+" "+name, func() error {
+		return g.once(ctx, fn)
+	})
+	return err
+}
+
+// once - Run one api request without retry, such as creating a fork
+func (g *gitlabList) once(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, gitlabTimeout)
+	defer cancel()
+	return fn(ctx)
+}
+
 func convertToRepo(base *map[string][]*Repo, projects []*gitlabapi.Project) {
 	for _, p := range projects {
 		g := p.PathWithNamespace[:strings.IndexByte(p.PathWithNamespace, '/')]
 		r := &Repo{
 			ID:       p.ID,
 			URL:      p.HTTPURLToRepo,
+			SSHURL:   p.SSHURLToRepo,
 			Name:     strings.TrimSpace(p.Name),
 			Group:    g,
 			FullPath: p.PathWithNamespace,
+
+			Topics:        knownTopics(p.Topics),
+			Visibility:    string(p.Visibility),
+			DefaultBranch: p.DefaultBranch,
+			Archived:      p.Archived,
+		}
+		if p.LastActivityAt != nil {
+			r.LastActivity = *p.LastActivityAt
 		}
 		// fmt.Printf("%s - %s\n", r.Group, r.URL)
 		ps, ok := (*base)[r.Group]
@@ -0,0 +1,73 @@
+package gitup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dannydd88/dd-go"
+	gitlabapi "gitlab.com/gitlab-org/api/client-go"
+)
+
+type gitlabSearch struct {
+	gitlabList
+}
+
+func (g *gitlabSearch) Search(ctx context.Context, r *Repo, query *string) ([]*CodeMatch, error) {
+	// ). prepare search options
+	opt := &gitlabapi.SearchOptions{
+		ListOptions: gitlabapi.ListOptions{
+			Page:    1,
+			PerPage: perPage,
+		},
+	}
+
+	// ). fetch all pages of blobs
+	result := []*CodeMatch{}
+	for {
+		var bs []*gitlabapi.Blob
+		var resp *gitlabapi.Response
+		err := g.call(ctx, fmt.Sprintf("search %s page %d", r.FullPath, opt.Page), func(ctx context.Context) error {
+			var err error
+			bs, resp, err = g.Api().Search.BlobsByProject(r.ID, dd.Val(query), opt, gitlabapi.WithContext(ctx))
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range bs {
+			result = append(result, convertBlob(b, dd.Val(query))...)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+// convertBlob - Lines of snippet |b| containing |query|, the first line if none does,
+//
+//	server side search ignores case
+func convertBlob(b *gitlabapi.Blob, query string) []*CodeMatch {
+	lines := strings.Split(strings.TrimRight(b.Data, "\n"), "\n")
+	var result []*CodeMatch
+	for i, l := range lines {
+		if strings.Contains(strings.ToLower(l), strings.ToLower(query)) {
+			result = append(result, &CodeMatch{
+				Path: b.Path,
+				Line: b.Startline + i,
+				Text: l,
+			})
+		}
+	}
+	if len(result) == 0 && len(lines) != 0 {
+		result = append(result, &CodeMatch{
+			Path: b.Path,
+			Line: b.Startline,
+			Text: lines[0],
+		})
+	}
+	return result
+}
@@ -0,0 +1,143 @@
+package gitup
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/dannydd88/gitup/pkg/git"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	TagGrep = "[grep]"
+)
+
+type GrepConfig struct {
+	Groups  []*string
+	Matcher *RepoMatcher
+	Pattern *regexp.Regexp
+	// Paths - only search files whose path or base name matches any of these globs, all if empty
+	Paths []string
+	// Query - plain text searched on server side for repos not cloned locally
+	Query *string
+}
+
+// GrepResult - matches of one repo in |Grep|
+type GrepResult struct {
+	Remote string
+	Repo   string
+	Path   string
+	// Server - repo is not cloned locally and searched on server side
+	Server  bool
+	Matches []*CodeMatch
+	Err     error
+}
+
+// Grep - search local clones of repos listed by |Api| under |Cwd|,
+// repos not cloned are searched by |Search| if it is provided, skipped otherwise
+type Grep struct {
+	Api        RepoList
+	Search     RepoSearch
+	GrepConfig *GrepConfig
+	Cwd        *string
+	TaskRunner dd.TaskRunner
+	Logger     dd.LevelLogger
+}
+
+// Go
+// Entrance of |grep|, return matches of each repo in listed order,
+// groups failed to list are returned with |Err|
+func (g *Grep) Go(ctx context.Context) []*GrepResult {
+	g.Logger.Info(TagGrep, "Started...")
+
+	// ). prepare repos
+	listing := listRepos(ctx, g.Api, g.GrepConfig.Groups, g.GrepConfig.Matcher, g.Logger, TagGrep)
+	results := []*GrepResult{}
+	for _, f := range listing.failures {
+		results = append(results, &GrepResult{
+			Repo: f.Repo,
+			Err:  f.Err,
+		})
+	}
+
+	// ). post search task to runner
+	wg := new(sync.WaitGroup)
+	skipped := 0
+	for _, repo := range listing.repos {
+		result := &GrepResult{
+			Repo: repo.FullPath,
+			Path: filepath.Join(dd.Val(g.Cwd), repo.FullPath),
+		}
+		if !IsGitRepo(result.Path) {
+			if g.Search == nil {
+				skipped++
+				continue
+			}
+			result.Server = true
+		}
+		results = append(results, result)
+
+		wg.Add(1)
+		c := dd.Bind4(g.doGrep, ctx, repo, result, wg)
+		g.TaskRunner.Post(c)
+	}
+	if skipped != 0 {
+		g.Logger.Info(TagGrep, "Skip repos not cloned ->", skipped)
+	}
+
+	g.Logger.Info(TagGrep, "Waiting searching repo...")
+	wg.Wait()
+	g.Logger.Info(TagGrep, "Done...")
+	return results
+}
+
+func (g *Grep) doGrep(
+	ctx context.Context,
+	repo *Repo,
+	result *GrepResult,
+	wg *sync.WaitGroup,
+) error {
+	defer wg.Done()
+
+	// ). repo waiting in queue when cancelled is not started at all
+	if ctx.Err() != nil {
+		result.Err = cancelledError(ctx)
+		return result.Err
+	}
+
+	// ). search on server side, which returns snippets around matches, keep lines matching pattern
+	if result.Server {
+		matches, err := g.Search.Search(ctx, repo, g.GrepConfig.Query)
+		if err != nil {
+			result.Err = err
+			return err
+		}
+		for _, m := range matches {
+			if g.GrepConfig.Pattern.MatchString(m.Text) && git.MatchPath(g.GrepConfig.Paths, m.Path) {
+				result.Matches = append(result.Matches, m)
+			}
+		}
+		return nil
+	}
+
+	// ). search local clone
+	matches, err := git.Grep(ctx, dd.Ptr(result.Path), &git.GrepOptions{
+		Pattern: g.GrepConfig.Pattern,
+		Paths:   g.GrepConfig.Paths,
+	})
+	if err != nil {
+		result.Err = err
+		return err
+	}
+	for _, m := range matches {
+		result.Matches = append(result.Matches, &CodeMatch{
+			Path: m.Path,
+			Line: m.Line,
+			Text: m.Text,
+		})
+	}
+	return nil
+}
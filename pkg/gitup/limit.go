@@ -0,0 +1,143 @@
+package gitup
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+type LimitConfig struct {
+	// RPS - requests per second to one host, 0 means unlimited
+	RPS float64
+	// Clones - concurrent git clone, fetch or pull to one host, 0 means unlimited
+	Clones int
+	// Hosts - overrides of |RPS| and |Clones| by host name
+	Hosts map[string]*HostLimitConfig
+}
+
+// HostLimitConfig - limits of one host, nil field means the default one of |LimitConfig|
+type HostLimitConfig struct {
+	RPS    *float64
+	Clones *int
+}
+
+// HostLimiter - limit api requests and git operations per host, nil means unlimited
+type HostLimiter struct {
+	config *LimitConfig
+	lock   sync.Mutex
+	hosts  map[string]*hostLimit
+}
+
+type hostLimit struct {
+	rate  *rate.Limiter
+	slots chan struct{}
+}
+
+// NewHostLimiter
+// Helper function to create |HostLimiter|, nil if nothing is limited
+func NewHostLimiter(config *LimitConfig) *HostLimiter {
+	if config == nil {
+		return nil
+	}
+	limited := config.RPS > 0 || config.Clones > 0
+	for _, h := range config.Hosts {
+		if h != nil && (h.RPS != nil || h.Clones != nil) {
+			limited = true
+		}
+	}
+	if !limited {
+		return nil
+	}
+	return &HostLimiter{
+		config: config,
+		hosts:  map[string]*hostLimit{},
+	}
+}
+
+// Wait - Block until a request to |host| is allowed or |ctx| is done
+func (l *HostLimiter) Wait(ctx context.Context, host string) error {
+	if l == nil {
+		return nil
+	}
+	h := l.get(host)
+	if h.rate == nil {
+		return nil
+	}
+	return h.rate.Wait(ctx)
+}
+
+// Acquire - Block until a git operation to |host| is allowed or |ctx| is done,
+// call the returned function when the operation is done
+func (l *HostLimiter) Acquire(ctx context.Context, host string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	h := l.get(host)
+	if h.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case h.slots <- struct{}{}:
+		return func() { <-h.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// RateLimiter - Rate limiter of requests to |host| for api clients, nil if unlimited
+func (l *HostLimiter) RateLimiter(host string) *rate.Limiter {
+	if l == nil {
+		return nil
+	}
+	return l.get(host).rate
+}
+
+func (l *HostLimiter) get(host string) *hostLimit {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	host = strings.ToLower(host)
+	if h, ok := l.hosts[host]; ok {
+		return h
+	}
+
+	// ). apply host override on default limits
+	rps, clones := l.config.RPS, l.config.Clones
+	if c, ok := l.config.Hosts[host]; ok && c != nil {
+		if c.RPS != nil {
+			rps = *c.RPS
+		}
+		if c.Clones != nil {
+			clones = *c.Clones
+		}
+	}
+
+	// ). build limit, burst of one request so that requests are evenly spread
+	h := &hostLimit{}
+	if rps > 0 {
+		h.rate = rate.NewLimiter(rate.Limit(rps), 1)
+	}
+	if clones > 0 {
+		h.slots = make(chan struct{}, clones)
+	}
+	l.hosts[host] = h
+	return h
+}
+
+// hostOf - Host name of an api url, git http url or scp-like "git@host:group/name.git"
+func hostOf(raw string) string {
+	if u, err := url.Parse(raw); err == nil && len(u.Host) != 0 {
+		return u.Hostname()
+	}
+	if i := strings.Index(raw, ":"); i >= 0 {
+		host := raw[:i]
+		if j := strings.LastIndex(host, "@"); j >= 0 {
+			host = host[j+1:]
+		}
+		return host
+	}
+	return ""
+}
@@ -0,0 +1,34 @@
+package gitup
+
+import (
+	"context"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	TagList = "[list]"
+)
+
+type ListConfig struct {
+	Groups  []*string
+	Matcher *RepoMatcher
+}
+
+// List - list repos by |Api| for inventory
+type List struct {
+	Api        RepoList
+	ListConfig *ListConfig
+	Logger     dd.LevelLogger
+}
+
+// Go
+// Entrance of |list|, return repos selected by matcher in listed order,
+// and listings failed with |Err|, so that an empty inventory is never mistaken for success,
+// listing stops once |ctx| is done
+func (l *List) Go(ctx context.Context) ([]*Repo, []*RepoResult) {
+	l.Logger.Info(TagList, "Started...")
+	listing := listRepos(ctx, l.Api, l.ListConfig.Groups, l.ListConfig.Matcher, l.Logger, TagList)
+	l.Logger.Info(TagList, "Done...")
+	return listing.repos, listing.failures
+}
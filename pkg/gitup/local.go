@@ -0,0 +1,162 @@
+package gitup
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dannydd88/gitup/pkg/git"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	TagLocal = "[local]"
+
+	localRemote = "origin"
+)
+
+type LocalConfig struct {
+	Root   *string
+	Logger dd.LevelLogger
+}
+
+// NewLocalList
+// Helper function to create |RepoList| implement from an existing checkout tree,
+// every git repository under |Root| becomes a |Repo| whose |FullPath| is its relative path
+func NewLocalList(config *LocalConfig) (RepoList, error) {
+	root := dd.Val(config.Root)
+	if len(root) == 0 {
+		root = "."
+	}
+	if !dd.DirExists(dd.Ptr(root)) {
+		return nil, fmt.Errorf("%s root not exist -> %s", TagLocal, root)
+	}
+
+	l := &localList{
+		root:   root,
+		logger: config.Logger,
+	}
+	return l, nil
+}
+
+type localList struct {
+	root   string
+	logger dd.LevelLogger
+}
+
+func (l *localList) Projects(ctx context.Context) ([]*Repo, error) {
+	return l.walk(ctx)
+}
+
+func (l *localList) ProjectsByGroup(ctx context.Context, group *string) ([]*Repo, error) {
+	repos, err := l.walk(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*Repo{}
+	for _, r := range repos {
+		if strings.HasPrefix(r.FullPath, dd.Val(group)) {
+			result = append(result, r)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%s Not find projects in %s", TagLocal, dd.Val(group))
+	}
+	return result, nil
+}
+
+func (l *localList) Project(ctx context.Context, group, name *string) (*Repo, error) {
+	repos, err := l.walk(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range repos {
+		if r.Group == dd.Val(group) && r.Name == dd.Val(name) {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("%s Not find project[%s][%s]", TagLocal, dd.Val(group), dd.Val(name))
+}
+
+// walk - Walk |root| for git repos, stop once |ctx| is done
+func (l *localList) walk(ctx context.Context) ([]*Repo, error) {
+	l.logger.Info(
+		TagLocal,
+		"Waiting walking repo...",
+		"[", "Root", "->", l.root, "]",
+	)
+
+	result := []*Repo{}
+	err := filepath.WalkDir(l.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		// skip hidden directories such as .git or state directories
+		if path != l.root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !IsGitRepo(path) {
+			return nil
+		}
+
+		// ). read origin remote
+		url, err := git.RemoteURL(dd.Ptr(path), localRemote)
+		if err != nil {
+			l.logger.Warn(TagLocal, "Skip repo without remote ->", path, err)
+			return filepath.SkipDir
+		}
+
+		// ). build repo from relative path
+		rel, err := filepath.Rel(l.root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			l.logger.Warn(TagLocal, "Skip root itself being a repo ->", path)
+			return nil
+		}
+		r := &Repo{
+			URL:      dd.Val(url),
+			Name:     filepath.Base(path),
+			FullPath: rel,
+		}
+		if i := strings.LastIndexByte(rel, '/'); i >= 0 {
+			r.Group = rel[:i]
+		}
+		result = append(result, r)
+
+		// nested repositories are not supported
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.logger.Info(TagLocal, "Done...")
+	return result, nil
+}
+
+// IsGitRepo - Check whether |path| is a non-bare or bare git repository
+func IsGitRepo(path string) bool {
+	if dd.FileExists(dd.Ptr(filepath.Join(path, ".git", "HEAD"))) {
+		return true
+	}
+	if !dd.FileExists(dd.Ptr(filepath.Join(path, "HEAD"))) {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(path, "objects"))
+	return err == nil && info.IsDir()
+}
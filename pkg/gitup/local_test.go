@@ -0,0 +1,92 @@
+package gitup
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dannydd88/dd-go"
+)
+
+// initRepo - Init a git repo at |rel| under |root| with origin |url|, bare if |bare|
+func initRepo(t *testing.T, root, rel, url string, bare bool) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	args := []string{"init", "--quiet"}
+	if bare {
+		args = append(args, "--bare")
+	}
+	for _, a := range [][]string{append(args, path), {"-C", path, "remote", "add", "origin", url}} {
+		if out, err := exec.Command("git", a...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %s\n%s", strings.Join(a, " "), err, out)
+		}
+	}
+}
+
+func TestLocalListWalksTree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	initRepo(t, root, "top", "https://git.example/top.git", false)
+	initRepo(t, root, "infra/tools/deploy", "https://git.example/infra/tools/deploy.git", false)
+	initRepo(t, root, "infra/mirror.git", "https://git.example/infra/mirror.git", true)
+	// nested repo inside another one is not listed
+	initRepo(t, root, "infra/tools/deploy/vendor/lib", "https://git.example/lib.git", false)
+	// hidden and state directories are skipped
+	initRepo(t, root, ".gitup/trash/old", "https://git.example/old.git", false)
+	initRepo(t, root, "infra/.cache/hidden", "https://git.example/hidden.git", false)
+	// a plain directory is walked through, a file is ignored
+	if err := os.MkdirAll(filepath.Join(root, "empty/dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "README"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := NewLocalList(&LocalConfig{Root: dd.Ptr(root), Logger: dd.NewLevelLogger(dd.ERROR)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos, err := l.Projects(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range repos {
+		got = append(got, r.Group+"|"+r.Name+"|"+r.FullPath+"|"+r.URL)
+	}
+	want := []string{
+		"infra|mirror.git|infra/mirror.git|https://git.example/infra/mirror.git",
+		"infra/tools|deploy|infra/tools/deploy|https://git.example/infra/tools/deploy.git",
+		"|top|top|https://git.example/top.git",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("repos = %v, want %v", got, want)
+	}
+
+	// ). lookups by group prefix and by group and name
+	byGroup, err := l.ProjectsByGroup(context.Background(), dd.Ptr("infra/tools"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fullPaths(byGroup), "infra/tools/deploy"; got != want {
+		t.Errorf("by group = %s, want %s", got, want)
+	}
+	if r, err := l.Project(context.Background(), dd.Ptr("infra"), dd.Ptr("mirror.git")); err != nil || r.FullPath != "infra/mirror.git" {
+		t.Errorf("project = %v, err = %v", r, err)
+	}
+}
+
+func TestNewLocalListMissingRoot(t *testing.T) {
+	_, err := NewLocalList(&LocalConfig{
+		Root:   dd.Ptr(filepath.Join(t.TempDir(), "missing")),
+		Logger: dd.NewLevelLogger(dd.ERROR),
+	})
+	if err == nil {
+		t.Error("missing root is accepted")
+	}
+}
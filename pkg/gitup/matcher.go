@@ -0,0 +1,210 @@
+package gitup
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	// regexPrefix - pattern with this prefix is a regular expression, glob otherwise
+	regexPrefix = "re:"
+)
+
+// MatcherConfig - patterns and attribute filters of |RepoMatcher|,
+// an attribute filter only drops repos whose provider knows the attribute,
+// repos with an unknown attribute are always kept, see |Repo|
+type MatcherConfig struct {
+	// Include & Exclude - patterns on |Repo.FullPath|
+	Include []*string
+	Exclude []*string
+	// Topics - repo should have any of them
+	Topics []*string
+	// Visibility - repo should have one of them
+	Visibility []*string
+	// Languages - primary language of repo should be one of them
+	Languages []*string
+	// ActiveWithin - repo should have activity within it such as "180d" or "12h"
+	ActiveWithin *string
+}
+
+// RepoMatcher - select repos by patterns on |Repo.FullPath| and repo attributes
+type RepoMatcher struct {
+	include      []*regexp.Regexp
+	exclude      []*regexp.Regexp
+	topics       map[string]bool
+	visibility   map[string]bool
+	languages    map[string]bool
+	activeWithin time.Duration
+}
+
+// NewRepoMatcher
+// Helper function to create |RepoMatcher|, each pattern is either a glob or a regex with "re:" prefix,
+// glob supports "*" in one path segment, "**" across segments and "?" for one character
+func NewRepoMatcher(config *MatcherConfig) (*RepoMatcher, error) {
+	m := &RepoMatcher{
+		topics:     toLowerSet(config.Topics),
+		visibility: toLowerSet(config.Visibility),
+		languages:  toLowerSet(config.Languages),
+	}
+	var err error
+	if m.include, err = compilePatterns(config.Include); err != nil {
+		return nil, err
+	}
+	if m.exclude, err = compilePatterns(config.Exclude); err != nil {
+		return nil, err
+	}
+	if config.ActiveWithin != nil {
+		if m.activeWithin, err = ParseDuration(dd.Val(config.ActiveWithin)); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Match - Whether |r| is selected, repo should match any include pattern if there is one,
+// should not match any exclude pattern, and should satisfy all attribute filters it has known value of
+func (m *RepoMatcher) Match(r *Repo) bool {
+	if m == nil {
+		return true
+	}
+	if len(m.include) != 0 && !matchAny(m.include, r.FullPath) {
+		return false
+	}
+	if matchAny(m.exclude, r.FullPath) {
+		return false
+	}
+	if len(m.topics) != 0 && r.Topics != nil {
+		found := false
+		for _, t := range r.Topics {
+			if m.topics[strings.ToLower(t)] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(m.visibility) != 0 && len(r.Visibility) != 0 && !m.visibility[strings.ToLower(r.Visibility)] {
+		return false
+	}
+	if len(m.languages) != 0 && len(r.Language) != 0 && !m.languages[strings.ToLower(r.Language)] {
+		return false
+	}
+	if m.activeWithin > 0 && !r.LastActivity.IsZero() && time.Since(r.LastActivity) > m.activeWithin {
+		return false
+	}
+	return true
+}
+
+// Filter - Keep repos that |Match|
+func (m *RepoMatcher) Filter(repos []*Repo) []*Repo {
+	if m == nil {
+		return repos
+	}
+	result := []*Repo{}
+	for _, r := range repos {
+		if m.Match(r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	for _, p := range patterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func compilePatterns(patterns []*string) ([]*regexp.Regexp, error) {
+	var result []*regexp.Regexp
+	for _, p := range patterns {
+		raw := dd.Val(p)
+		if len(raw) == 0 {
+			continue
+		}
+		var expr string
+		if strings.HasPrefix(raw, regexPrefix) {
+			expr = strings.TrimPrefix(raw, regexPrefix)
+		} else {
+			expr = globToRegex(raw)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s -> %s", raw, err)
+		}
+		result = append(result, re)
+	}
+	return result, nil
+}
+
+func globToRegex(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			// any leading directories, including none
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			// the directory itself and anything under it
+			b.WriteString("(/.*)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// ParseDuration - Same as |time.ParseDuration| but also accept "d" for days and "w" for weeks
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %s", s)
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+func toLowerSet(values []*string) map[string]bool {
+	result := map[string]bool{}
+	for _, v := range values {
+		// also accept comma separated values
+		for _, s := range strings.Split(dd.Val(v), ",") {
+			s = strings.ToLower(strings.TrimSpace(s))
+			if len(s) != 0 {
+				result[s] = true
+			}
+		}
+	}
+	return result
+}
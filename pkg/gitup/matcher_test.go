@@ -0,0 +1,104 @@
+package gitup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dannydd88/dd-go"
+)
+
+func TestRepoMatcherKeepsUnknownAttributes(t *testing.T) {
+	m, err := NewRepoMatcher(&MatcherConfig{
+		Topics:       dd.PtrSlice([]string{"backend"}),
+		Visibility:   dd.PtrSlice([]string{"public,internal"}),
+		Languages:    dd.PtrSlice([]string{"Go"}),
+		ActiveWithin: dd.Ptr("30d"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	known := func() *Repo {
+		return &Repo{
+			FullPath:     "acme/a",
+			Topics:       []string{"Backend"},
+			Visibility:   "public",
+			Language:     "go",
+			LastActivity: time.Now(),
+		}
+	}
+	for name, c := range map[string]struct {
+		change func(r *Repo)
+		want   bool
+	}{
+		"all known and matched": {func(r *Repo) {}, true},
+		"unknown topics":        {func(r *Repo) { r.Topics = nil }, true},
+		"no topic":              {func(r *Repo) { r.Topics = []string{} }, false},
+		"other topic":           {func(r *Repo) { r.Topics = []string{"web"} }, false},
+		"unknown visibility":    {func(r *Repo) { r.Visibility = "" }, true},
+		"other visibility":      {func(r *Repo) { r.Visibility = "private" }, false},
+		"unknown language":      {func(r *Repo) { r.Language = "" }, true},
+		"other language":        {func(r *Repo) { r.Language = "Rust" }, false},
+		"unknown activity":      {func(r *Repo) { r.LastActivity = time.Time{} }, true},
+		"inactive":              {func(r *Repo) { r.LastActivity = time.Now().Add(-31 * 24 * time.Hour) }, false},
+	} {
+		r := known()
+		c.change(r)
+		if got := m.Match(r); got != c.want {
+			t.Errorf("%s: match = %v, want %v", name, got, c.want)
+		}
+	}
+}
+
+func TestRepoMatcherPatterns(t *testing.T) {
+	for _, c := range []struct {
+		include, exclude []string
+		path             string
+		want             bool
+	}{
+		// "**" crosses segments, including none
+		{[]string{"infra/**"}, nil, "infra/a", true},
+		{[]string{"infra/**"}, nil, "infra/tools/deploy/a", true},
+		{[]string{"**/a"}, nil, "a", true},
+		{[]string{"**/a"}, nil, "infra/tools/a", true},
+		{[]string{"infra/**"}, nil, "infrastructure/a", false},
+		// "/**" suffix is the directory itself and anything under it
+		{nil, []string{"infra/legacy/**"}, "infra/legacy", false},
+		{nil, []string{"infra/legacy/**"}, "infra/legacy/old/a", false},
+		{nil, []string{"infra/legacy/**"}, "infra/legacy-new", true},
+		// "*" and "?" stay in one segment
+		{nil, []string{"*/sandbox-*"}, "acme/sandbox-a", false},
+		{nil, []string{"*/sandbox-*"}, "acme/team/sandbox-a", true},
+		{nil, []string{"*/sandbox-*"}, "acme/sandbox-a/b", true},
+		{[]string{"acme/?"}, nil, "acme/a", true},
+		{[]string{"acme/?"}, nil, "acme/ab", false},
+		// regex with "re:" prefix is not anchored unless written so
+		{[]string{`re:^acme/(a|b)$`}, nil, "acme/b", true},
+		{[]string{`re:^acme/(a|b)$`}, nil, "acme/c", false},
+		{[]string{`re:sandbox`}, nil, "acme/team-sandbox/a", true},
+		// glob special characters of regex are literal
+		{[]string{"acme/a.b"}, nil, "acme/axb", false},
+		// exclude wins over include
+		{[]string{"acme/**"}, []string{"acme/legacy/**"}, "acme/legacy/a", false},
+		{[]string{"acme/**"}, []string{"acme/legacy/**"}, "acme/a", true},
+		// no include means everything not excluded
+		{nil, []string{"acme/legacy/**"}, "other/a", true},
+	} {
+		m, err := NewRepoMatcher(&MatcherConfig{
+			Include: dd.PtrSlice(c.include),
+			Exclude: dd.PtrSlice(c.exclude),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.Match(&Repo{FullPath: c.path}); got != c.want {
+			t.Errorf("include %v exclude %v: match %s = %v, want %v", c.include, c.exclude, c.path, got, c.want)
+		}
+	}
+}
+
+func TestRepoMatcherInvalidRegex(t *testing.T) {
+	if _, err := NewRepoMatcher(&MatcherConfig{Include: dd.PtrSlice([]string{"re:("})}); err == nil {
+		t.Error("invalid regex is accepted")
+	}
+}
@@ -0,0 +1,287 @@
+package gitup
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	progressInterval  = 200 * time.Millisecond
+	progressMaxActive = 10
+	progressMinWidth  = 20
+)
+
+// Progress - live progress of repos in |Sync| and |Fork|, nil means plain log lines only
+type Progress interface {
+	// Start - |total| repos are going to be processed
+	Start(total int)
+
+	// Begin - |repo| starts to run |action|
+	Begin(repo, action string)
+
+	// Update - |line| is the latest transfer progress of |repo| from git sideband
+	Update(repo, line string)
+
+	// Finish - |repo| is done, |err| is nil on success
+	Finish(repo string, err error)
+
+	// Stop - All repos are done, leave the final summary on screen
+	Stop()
+}
+
+// nopProgress - used when no |Progress| is provided
+type nopProgress struct{}
+
+func (nopProgress) Start(int)             {}
+func (nopProgress) Begin(string, string)  {}
+func (nopProgress) Update(string, string) {}
+func (nopProgress) Finish(string, error)  {}
+func (nopProgress) Stop()                 {}
+
+// NewProgressWriter - Writer of git sideband progress of |repo|, which reports the latest line to |p|
+func NewProgressWriter(p Progress, repo string) io.Writer {
+	return &progressWriter{
+		progress: p,
+		repo:     repo,
+	}
+}
+
+type progressWriter struct {
+	progress Progress
+	repo     string
+	buf      []byte
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	// ). sideband progress ends each update with "\r" and each stage with "\n"
+	w.buf = append(w.buf, p...)
+	i := bytes.LastIndexAny(w.buf, "\r\n")
+	if i < 0 {
+		return len(p), nil
+	}
+	lines := strings.FieldsFunc(string(w.buf[:i]), func(r rune) bool {
+		return r == '\r' || r == '\n'
+	})
+	w.buf = append(w.buf[:0], w.buf[i+1:]...)
+
+	// ). only the latest line is interesting
+	for j := len(lines) - 1; j >= 0; j-- {
+		if line := strings.TrimSpace(lines[j]); len(line) != 0 {
+			w.progress.Update(w.repo, line)
+			break
+		}
+	}
+	return len(p), nil
+}
+
+// TerminalProgress - |Progress| drawn on an interactive terminal, redrawn in place,
+//
+//	it is also an |io.Writer| so that log lines are printed above the progress
+type TerminalProgress struct {
+	w     io.Writer
+	name  string
+	width func() int
+
+	lock      sync.Mutex
+	total     int
+	done      int
+	failed    int
+	start     time.Time
+	active    map[string]*activeRepo
+	order     []string
+	lines     int
+	stopped   bool
+	startOnce sync.Once
+	stopOnce  sync.Once
+	quit      chan struct{}
+}
+
+type activeRepo struct {
+	action string
+	line   string
+}
+
+// NewTerminalProgress
+// Helper function to create |TerminalProgress| drawing on |w| which should be a terminal,
+// |name| prefixes the summary line, |width| returns the terminal width, 0 if unknown
+func NewTerminalProgress(w io.Writer, name string, width func() int) *TerminalProgress {
+	return &TerminalProgress{
+		w:      w,
+		name:   name,
+		width:  width,
+		start:  time.Now(),
+		active: map[string]*activeRepo{},
+		quit:   make(chan struct{}),
+	}
+}
+
+func (t *TerminalProgress) Start(total int) {
+	t.lock.Lock()
+	t.total += total
+	t.lock.Unlock()
+
+	// ). one ticker for the whole run, |Start| may be called for each remote
+	t.startOnce.Do(func() {
+		go t.loop()
+	})
+}
+
+func (t *TerminalProgress) Begin(repo, action string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if _, ok := t.active[repo]; !ok {
+		t.order = append(t.order, repo)
+	}
+	t.active[repo] = &activeRepo{action: action}
+}
+
+func (t *TerminalProgress) Update(repo, line string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if a, ok := t.active[repo]; ok {
+		a.line = line
+	}
+}
+
+func (t *TerminalProgress) Finish(repo string, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.done++
+	if err != nil {
+		t.failed++
+	}
+	delete(t.active, repo)
+	for i, r := range t.order {
+		if r == repo {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
+}
+
+func (t *TerminalProgress) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.quit)
+
+		// ). keep only the summary line on screen
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		t.clear()
+		fmt.Fprintln(t.w, t.summary())
+		t.stopped = true
+	})
+}
+
+// Write - Print |p| above the progress
+func (t *TerminalProgress) Write(p []byte) (int, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.stopped {
+		return t.w.Write(p)
+	}
+	t.clear()
+	n, err := t.w.Write(p)
+	t.draw()
+	return n, err
+}
+
+// Pause - Run |fn| with progress erased and not redrawn, such as a prompt on the same terminal,
+// progress is drawn again below whatever |fn| printed once it returns
+func (t *TerminalProgress) Pause(fn func()) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.stopped {
+		fn()
+		return
+	}
+	t.clear()
+	fn()
+	t.draw()
+}
+
+func (t *TerminalProgress) loop() {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.lock.Lock()
+			if !t.stopped {
+				t.clear()
+				t.draw()
+			}
+			t.lock.Unlock()
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+// clear - Erase lines drawn last time, cursor is left at the start of the first one
+func (t *TerminalProgress) clear() {
+	if t.lines == 0 {
+		return
+	}
+	fmt.Fprintf(t.w, "\x1b[%dA\r\x1b[J", t.lines)
+	t.lines = 0
+}
+
+func (t *TerminalProgress) draw() {
+	lines := []string{t.summary()}
+	for i, r := range t.order {
+		if i == progressMaxActive {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(t.order)-i))
+			break
+		}
+		a := t.active[r]
+		line := fmt.Sprintf("  %s [%s]", r, a.action)
+		if len(a.line) != 0 {
+			line += " " + a.line
+		}
+		lines = append(lines, line)
+	}
+
+	// ). lines longer than terminal wrap and break the erasing, so cut them
+	width := 0
+	if t.width != nil {
+		width = t.width()
+	}
+	for _, l := range lines {
+		fmt.Fprintln(t.w, truncate(l, width))
+	}
+	t.lines = len(lines)
+}
+
+func (t *TerminalProgress) summary() string {
+	elapsed := time.Since(t.start).Round(time.Second)
+	s := fmt.Sprintf(
+		"%s %d/%d done, %d failed, %d active, elapsed %s",
+		t.name,
+		t.done,
+		t.total,
+		t.failed,
+		len(t.active),
+		elapsed,
+	)
+	if t.done != 0 && t.done < t.total {
+		eta := time.Duration(float64(time.Since(t.start)) / float64(t.done) * float64(t.total-t.done))
+		s += fmt.Sprintf(", eta %s", eta.Round(time.Second))
+	}
+	return s
+}
+
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	width = max(width-1, progressMinWidth)
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	return string(r[:width-3]) + "..."
+}
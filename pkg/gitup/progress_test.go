@@ -0,0 +1,35 @@
+package gitup
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTerminalProgressPauseKeepsPrompt(t *testing.T) {
+	var out bytes.Buffer
+	p := NewTerminalProgress(&out, "[test]", nil)
+	p.Start(1)
+	p.Begin("acme/a", "clone")
+	time.Sleep(2 * progressInterval)
+
+	// ). nothing is drawn or erased while paused
+	var during string
+	p.Pause(func() {
+		out.WriteString("Delete? [y/N] ")
+		before := out.Len()
+		time.Sleep(3 * progressInterval)
+		during = out.String()[before:]
+	})
+	if len(during) != 0 {
+		t.Errorf("written while paused = %q", during)
+	}
+
+	// ). progress is drawn again right below the prompt, which is never erased
+	p.Stop()
+	after := out.String()[strings.Index(out.String(), "Delete? [y/N] "):]
+	if !strings.HasPrefix(after, "Delete? [y/N] [test] 0/1 done") {
+		t.Errorf("output after pause = %q", after)
+	}
+}
@@ -0,0 +1,157 @@
+package gitup
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	// ActionOrphan - local clone no longer corresponds to any listed repo
+	ActionOrphan = "orphan"
+
+	PruneReport = "report"
+	PruneTrash  = "trash"
+	PruneDelete = "delete"
+
+	trashDir = "trash"
+)
+
+// findOrphans - Relative paths of local clones under listed groups that are not listed any more
+func (s *Sync) findOrphans(l *syncListing) []string {
+	// ). a partial listing would turn live clones into orphans
+	if !l.complete {
+		s.Logger.Warn(TagSync, "Listing is incomplete, skip orphan detection")
+		return nil
+	}
+
+	// ). an empty listing usually means listing failed, never treat everything as orphan
+	if len(l.all) == 0 {
+		s.Logger.Warn(TagSync, "Nothing listed, skip orphan detection")
+		return nil
+	}
+
+	known := map[string]bool{}
+	for _, r := range l.all {
+		known[r.FullPath] = true
+	}
+
+	// ). walk every listed group
+	cwd := dd.Val(s.Cwd)
+	found := map[string]bool{}
+	for _, root := range l.roots {
+		dir := filepath.Join(cwd, root)
+		if !dd.DirExists(dd.Ptr(dir)) {
+			continue
+		}
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			// skip hidden directories such as .git or state directories
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if !IsGitRepo(path) {
+				return nil
+			}
+			rel, err := filepath.Rel(cwd, path)
+			if err != nil {
+				return nil
+			}
+			rel = filepath.ToSlash(rel)
+			if rel != "." && !known[rel] {
+				found[rel] = true
+			}
+			return filepath.SkipDir
+		})
+	}
+
+	result := []string{}
+	for o := range found {
+		result = append(result, o)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (s *Sync) pruneMode() string {
+	switch m := strings.ToLower(dd.Val(s.SyncConfig.Prune)); m {
+	case PruneTrash, PruneDelete:
+		return m
+	default:
+		return PruneReport
+	}
+}
+
+// prune - Report, trash or delete orphaned clones according to |SyncConfig.Prune|
+func (s *Sync) prune(l *syncListing) []*RepoResult {
+	orphans := s.findOrphans(l)
+	if len(orphans) == 0 {
+		return nil
+	}
+
+	// ). prepare results, action is the prune mode
+	cwd := dd.Val(s.Cwd)
+	mode := s.pruneMode()
+	results := []*RepoResult{}
+	for _, o := range orphans {
+		results = append(results, &RepoResult{
+			Repo:   o,
+			Path:   filepath.Join(cwd, o),
+			Action: ActionOrphan,
+		})
+	}
+
+	switch mode {
+	case PruneTrash:
+		// ). move into a timestamped trash directory, so that it can be restored
+		trash := filepath.Join(cwd, stateDir, trashDir, time.Now().Format("20060102-150405"))
+		for _, r := range results {
+			r.Action = PruneTrash
+			to := filepath.Join(trash, r.Repo)
+			if r.Err = os.MkdirAll(filepath.Dir(to), os.ModePerm); r.Err != nil {
+				s.Logger.Warn(TagSync, "Trash orphan error ->", r.Repo, r.Err)
+				continue
+			}
+			if r.Err = os.Rename(r.Path, to); r.Err != nil {
+				s.Logger.Warn(TagSync, "Trash orphan error ->", r.Repo, r.Err)
+				continue
+			}
+			r.Updated = true
+			s.Logger.Info(TagSync, "Trash orphan", "[", r.Repo, "]->[", to, "]")
+		}
+
+	case PruneDelete:
+		// ). delete only after confirmation
+		paths := []string{}
+		for _, r := range results {
+			paths = append(paths, r.Path)
+		}
+		if s.SyncConfig.ConfirmPrune == nil || !s.SyncConfig.ConfirmPrune(paths) {
+			s.Logger.Warn(TagSync, "Delete orphans not confirmed, skip ->", len(paths))
+			return results
+		}
+		for _, r := range results {
+			r.Action = PruneDelete
+			if r.Err = os.RemoveAll(r.Path); r.Err != nil {
+				s.Logger.Warn(TagSync, "Delete orphan error ->", r.Path, r.Err)
+				continue
+			}
+			r.Updated = true
+			s.Logger.Info(TagSync, "Delete orphan ->", r.Path)
+		}
+
+	default:
+		for _, r := range results {
+			s.Logger.Warn(TagSync, "Orphan repo ->", r.Path)
+		}
+		s.Logger.Info(TagSync, "Find orphans ->", len(orphans), ", use --prune trash|delete to clean them")
+	}
+	return results
+}
@@ -1,34 +1,74 @@
 package gitup
 
+import (
+	"context"
+	"time"
+)
+
 // Repo represent a repository
 type Repo struct {
 	ID       int
 	URL      string
+	SSHURL   string
 	Name     string
 	Group    string
 	FullPath string
+
+	// attributes below are empty if the provider does not know them,
+	// nil |Topics| is unknown while an empty one means no topic
+	Topics        []string
+	Visibility    string
+	Language      string
+	LastActivity  time.Time
+	DefaultBranch string
+	Archived      bool
 }
 
-// RepoList - represent a set of list operations of all repositories
+// RepoList - represent a set of list operations of all repositories,
+// requests are cancelled once |ctx| is done
 type RepoList interface {
-	Projects() []*Repo
+	// Projects - List all projects visible to current user
+	Projects(ctx context.Context) ([]*Repo, error)
 
 	// ProjectsByGroup - List project by group name prefix match
-	ProjectsByGroup(group *string) ([]*Repo, error)
+	ProjectsByGroup(ctx context.Context, group *string) ([]*Repo, error)
 
 	// Project - Filter target project by specific group and name
-	Project(group, name *string) (*Repo, error)
+	Project(ctx context.Context, group, name *string) (*Repo, error)
 }
 
 // RepoFork - represent a set of fork operations to fork any repositories
 type RepoFork interface {
 	RepoList
 
-	Fork(r *Repo, group *string) (*Repo, error)
+	Fork(ctx context.Context, r *Repo, group *string) (*Repo, error)
+
+	Rename(ctx context.Context, r *Repo, name *string) (*Repo, error)
+
+	Transfer(ctx context.Context, r *Repo, group *string) (*Repo, error)
+
+	DeleteForkRelationship(ctx context.Context, r *Repo) (bool, error)
+}
 
-	Rename(r *Repo, name *string) (*Repo, error)
+// CodeMatch - one matched line of a file in a repo
+type CodeMatch struct {
+	Path string
+	Line int
+	Text string
+}
 
-	Transfer(r *Repo, group *string) (*Repo, error)
+// RepoSearch - represent a set of list operations plus code search on server side
+type RepoSearch interface {
+	RepoList
+
+	// Search - Search |query| in files of default branch of |r|
+	Search(ctx context.Context, r *Repo, query *string) ([]*CodeMatch, error)
+}
 
-	DeleteForkRelationship(r *Repo) (bool, error)
+// knownTopics - |topics| of a provider which always reports topics, so that no topic is not unknown
+func knownTopics(topics []string) []string {
+	if topics == nil {
+		return []string{}
+	}
+	return topics
 }
@@ -0,0 +1,174 @@
+package gitup
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ReportJSON  = "json"
+	ReportJUnit = "junit"
+	ReportCSV   = "csv"
+)
+
+// RepoResult - what happened to one repo in |Sync| or |Fork|
+type RepoResult struct {
+	Remote   string
+	Repo     string
+	Path     string
+	Action   string
+	Updated  bool
+	Err      error
+	Retries  int
+	Duration time.Duration
+	// Skipped - why the repo is left untouched, empty if it is not skipped
+	Skipped string
+}
+
+// Failed - Whether the operation on this repo failed
+func (r *RepoResult) Failed() bool {
+	return r.Err != nil
+}
+
+type jsonResult struct {
+	Remote   string  `json:"remote,omitempty"`
+	Repo     string  `json:"repo"`
+	Path     string  `json:"path"`
+	Action   string  `json:"action"`
+	Updated  bool    `json:"updated"`
+	Error    string  `json:"error,omitempty"`
+	Retries  int     `json:"retries,omitempty"`
+	Duration float64 `json:"duration"`
+	Skipped  string  `json:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitSuite struct {
+	XMLName  xml.Name     `xml:"testsuite"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Cases    []*junitCase `xml:"testcase"`
+}
+
+// WriteReport - Write |results| into |w| in |format|, |name| is the suite name of junit report
+func WriteReport(w io.Writer, format, name string, results []*RepoResult) error {
+	switch strings.ToLower(format) {
+	case ReportJSON:
+		list := []*jsonResult{}
+		for _, r := range results {
+			j := &jsonResult{
+				Remote:   r.Remote,
+				Repo:     r.Repo,
+				Path:     r.Path,
+				Action:   r.Action,
+				Updated:  r.Updated,
+				Retries:  r.Retries,
+				Duration: r.Duration.Seconds(),
+				Skipped:  r.Skipped,
+			}
+			if r.Err != nil {
+				j.Error = r.Err.Error()
+			}
+			list = append(list, j)
+		}
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(list)
+
+	case ReportJUnit:
+		suite := &junitSuite{
+			Name:  name,
+			Tests: len(results),
+		}
+		var total time.Duration
+		for _, r := range results {
+			c := &junitCase{
+				Name:      r.Repo,
+				ClassName: strings.TrimPrefix(r.Remote+"."+r.Action, "."),
+				Time:      formatSeconds(r.Duration),
+			}
+			if r.Retries != 0 {
+				c.SystemOut = fmt.Sprintf("retries: %d", r.Retries)
+			}
+			if r.Err != nil {
+				suite.Failures++
+				c.Failure = &junitFailure{
+					Message: r.Err.Error(),
+					Text:    r.Path,
+				}
+			} else if len(r.Skipped) != 0 {
+				suite.Skipped++
+				c.Skipped = &junitSkipped{Message: r.Skipped}
+			}
+			total += r.Duration
+			suite.Cases = append(suite.Cases, c)
+		}
+		suite.Time = formatSeconds(total)
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		e := xml.NewEncoder(w)
+		e.Indent("", "  ")
+		if err := e.Encode(suite); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+
+	case ReportCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"remote", "repo", "path", "action", "updated", "error", "retries", "duration", "skipped"})
+		for _, r := range results {
+			var e string
+			if r.Err != nil {
+				e = r.Err.Error()
+			}
+			cw.Write([]string{
+				r.Remote,
+				r.Repo,
+				r.Path,
+				r.Action,
+				strconv.FormatBool(r.Updated),
+				e,
+				strconv.Itoa(r.Retries),
+				formatSeconds(r.Duration),
+				r.Skipped,
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default:
+		return fmt.Errorf("unsupport report format -> %s", format)
+	}
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
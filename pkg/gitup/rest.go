@@ -0,0 +1,187 @@
+package gitup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	restTimeout = 1 * time.Minute
+)
+
+// restError - error response of a REST api
+type restError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *restError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+var restNextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// RestApi - json over http requests to providers paginated by Link header, such as github and gitea
+type RestApi interface {
+	// Get - Send a GET request and decode json result into |out|, retried on transient failures
+	//       |string| is the url of next page, empty if it is the last one
+	Get(ctx context.Context, path string, query url.Values, out any) (string, error)
+
+	// Send - Send a request with json body and decode json result into |out|, never retried
+	//        since server may have applied a try which looks failed
+	Send(ctx context.Context, method, path string, in, out any) (int, error)
+
+	// Logger - Return the current logger for logging
+	Logger() dd.LevelLogger
+}
+
+// newRestApi - |RestApi| sending requests to |baseURL| with |header|
+func newRestApi(baseURL string, header http.Header, retry *RetryPolicy, limiter *HostLimiter, logger dd.LevelLogger) RestApi {
+	return &restContext{
+		client: newRestClient(baseURL, header, retry, limiter),
+		logger: logger,
+	}
+}
+
+type restContext struct {
+	client *restClient
+	logger dd.LevelLogger
+}
+
+func (r *restContext) Get(ctx context.Context, path string, query url.Values, out any) (string, error) {
+	resp, err := r.client.get(ctx, r.logger, path, query, out)
+	if err != nil {
+		return "", err
+	}
+	m := restNextLink.FindStringSubmatch(resp.Header.Get("Link"))
+	if len(m) != 2 {
+		return "", nil
+	}
+	return m[1], nil
+}
+
+func (r *restContext) Send(ctx context.Context, method, path string, in, out any) (int, error) {
+	resp, err := r.client.do(ctx, method, path, nil, in, out)
+	if resp == nil {
+		return 0, err
+	}
+	return resp.StatusCode, err
+}
+
+func (r *restContext) Logger() dd.LevelLogger {
+	return r.logger
+}
+
+// restClient - a tiny json over http client shared by the REST based providers
+type restClient struct {
+	baseURL string
+	header  http.Header
+	client  *http.Client
+	retry   *RetryPolicy
+	limiter *HostLimiter
+}
+
+func newRestClient(baseURL string, header http.Header, retry *RetryPolicy, limiter *HostLimiter) *restClient {
+	return &restClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		header:  header,
+		client:  &http.Client{Timeout: restTimeout},
+		retry:   retry,
+		limiter: limiter,
+	}
+}
+
+// get - Send a GET request by |do|, retried by |retry| since reading is safe to repeat,
+// |logger| is used for logging retries
+func (c *restClient) get(ctx context.Context, logger dd.LevelLogger, path string, query url.Values, out any) (*http.Response, error) {
+	var resp *http.Response
+	_, err := c.retry.Do(ctx, logger, http.MethodGet+" "+path, func() error {
+		var err error
+		resp, err = c.do(ctx, http.MethodGet, path, query, nil, out)
+		return err
+	})
+	return resp, err
+}
+
+// do - Send one request, |path| can be relative to |baseURL| or an absolute url,
+//
+//	|in| will be encoded as json body if not nil,
+//	|out| will be decoded from json response if not nil,
+//	request is cancelled once |ctx| is done
+func (c *restClient) do(ctx context.Context, method, path string, query url.Values, in, out any) (*http.Response, error) {
+	// ). build url
+	target := path
+	if !strings.Contains(path, "://") {
+		target = c.baseURL + "/" + strings.TrimPrefix(path, "/")
+	}
+	if len(query) != 0 {
+		if strings.Contains(target, "?") {
+			target += "&" + query.Encode()
+		} else {
+			target += "?" + query.Encode()
+		}
+	}
+
+	// ). build body
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	// ). build request
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// ). do request when rate limit allows
+	if err := c.limiter.Wait(ctx, req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return resp, &restError{
+			Method:     method,
+			URL:        target,
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(data)),
+		}
+	}
+
+	// ). decode response
+	if out != nil && len(data) != 0 {
+		err = json.Unmarshal(data, out)
+	}
+	return resp, err
+}
@@ -0,0 +1,135 @@
+package gitup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	// restForkPoll & restForkWait - how often and how long to wait for an asynchronous fork to exist
+	restForkPoll = 2 * time.Second
+	restForkWait = 5 * time.Minute
+)
+
+// restFork - |RepoFork| operations shared by github like apis
+type restFork struct {
+	list *restList
+	// pollInterval - interval of polling a new fork, |restForkPoll| if not set
+	pollInterval time.Duration
+}
+
+func (f *restFork) Fork(ctx context.Context, r *Repo, group *string) (*Repo, error) {
+	// ). prepare fork options, nil |group| means fork into current user
+	opt := map[string]any{}
+	if group != nil {
+		opt["organization"] = dd.Val(group)
+	}
+
+	// ). do fork
+	p := new(restRepository)
+	status, err := f.list.Send(ctx, http.MethodPost, fmt.Sprintf("repos/%s/forks", r.FullPath), opt, p)
+	if err != nil {
+		return nil, err
+	}
+	f.list.Logger().Info(
+		f.list.tag,
+		"Fork finish,",
+		"http ->", status,
+		",",
+		"new project ->", p.ID,
+	)
+
+	// ). github creates forks asynchronously, wait until it exists before any further change
+	p, err = f.waitForked(ctx, p.FullName)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.list.convert(p), nil
+}
+
+// waitForked - Poll repository |fullPath| until it exists, or |restForkWait| passes
+func (f *restFork) waitForked(ctx context.Context, fullPath string) (*restRepository, error) {
+	interval := f.pollInterval
+	if interval <= 0 {
+		interval = restForkPoll
+	}
+	ctx, cancel := context.WithTimeout(ctx, restForkWait)
+	defer cancel()
+	for {
+		p := new(restRepository)
+		_, err := f.list.Get(ctx, fmt.Sprintf("repos/%s", fullPath), nil, p)
+		var re *restError
+		if err == nil {
+			return p, nil
+		} else if !errors.As(err, &re) || re.StatusCode != http.StatusNotFound {
+			return nil, err
+		}
+		f.list.Logger().Debug(f.list.tag, "Waiting fork ->", fullPath)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%s Fork %s not ready -> %s", f.list.tag, fullPath, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (f *restFork) Rename(ctx context.Context, r *Repo, name *string) (*Repo, error) {
+	// ). prepare edit repository options
+	opt := map[string]any{
+		"name": dd.Val(name),
+	}
+
+	// ). do rename
+	p := new(restRepository)
+	status, err := f.list.Send(ctx, http.MethodPatch, fmt.Sprintf("repos/%s", r.FullPath), opt, p)
+	if err != nil {
+		return nil, err
+	}
+	f.list.Logger().Info(
+		f.list.tag,
+		"Rename finish,",
+		"http ->", status,
+		",",
+		"project ->", r.ID,
+		",",
+		"after ->", p.ID,
+	)
+
+	return f.list.convert(p), nil
+}
+
+func (f *restFork) Transfer(ctx context.Context, r *Repo, group *string) (*Repo, error) {
+	// ). prepare transfer options
+	opt := map[string]any{
+		"new_owner": dd.Val(group),
+	}
+
+	// ). do transfer
+	p := new(restRepository)
+	status, err := f.list.Send(ctx, http.MethodPost, fmt.Sprintf("repos/%s/transfer", r.FullPath), opt, p)
+	if err != nil {
+		return nil, err
+	}
+	f.list.Logger().Info(
+		f.list.tag,
+		"Transfer finish,",
+		"http ->", status,
+		",",
+		"project ->", r.ID,
+		",",
+		"after ->", p.ID,
+	)
+
+	return f.list.convert(p), nil
+}
+
+func (f *restFork) DeleteForkRelationship(ctx context.Context, r *Repo) (bool, error) {
+	// ). neither github nor gitea provides an api to detach a fork
+	return false, fmt.Errorf("%s Delete fork relationship is not supported, project -> %d", f.list.tag, r.ID)
+}
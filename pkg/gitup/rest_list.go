@@ -0,0 +1,156 @@
+package gitup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dannydd88/dd-go"
+)
+
+type restOwner struct {
+	Login string `json:"login"`
+}
+
+// restRepository - repository payload of github like apis, fields unknown to a provider stay empty
+type restRepository struct {
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	FullName      string    `json:"full_name"`
+	CloneURL      string    `json:"clone_url"`
+	SSHURL        string    `json:"ssh_url"`
+	Archived      bool      `json:"archived"`
+	Owner         restOwner `json:"owner"`
+	Topics        []string  `json:"topics"`
+	Private       bool      `json:"private"`
+	DefaultBranch string    `json:"default_branch"`
+	Language      string    `json:"language"`
+
+	// github only
+	Visibility string    `json:"visibility"`
+	PushedAt   time.Time `json:"pushed_at"`
+
+	// gitea only
+	Internal  bool      `json:"internal"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// restSearchResult - repositories wrapped in |data|, such as gitea search api
+type restSearchResult struct {
+	OK   bool              `json:"ok"`
+	Data []*restRepository `json:"data"`
+}
+
+// restList - |RepoList| parts shared by github like apis, where a group is an org or a user
+// and the rest of group path is a repo prefix
+type restList struct {
+	RestApi
+	tag            string
+	filterArchived bool
+	// pageParam - query key of page size, |pageSize| is its value
+	pageParam string
+	pageSize  int
+	// convert - convert payload into |Repo| in the way of the provider
+	convert func(p *restRepository) *Repo
+}
+
+func (l *restList) ProjectsByGroup(ctx context.Context, group *string) ([]*Repo, error) {
+	// ). only one level of owner, the rest is a repo prefix
+	owner := dd.Val(group)
+	subSearch := false
+	if strings.Contains(owner, "/") {
+		owner = owner[:strings.IndexByte(owner, '/')]
+		subSearch = true
+	}
+	if len(owner) == 0 {
+		return nil, fmt.Errorf("%s Empty group", l.tag)
+	}
+
+	// ). try as organization first, then fallback to user only if no such organization,
+	//    other errors such as auth failures or server errors would fail as user as well
+	result, err := l.fetchRepositories(ctx, fmt.Sprintf("orgs/%s/repos", url.PathEscape(owner)), nil, false)
+	var re *restError
+	if errors.As(err, &re) && re.StatusCode == http.StatusNotFound {
+		l.Logger().Debug(l.tag, "No such org, try user ->", owner)
+		result, err = l.fetchRepositories(ctx, fmt.Sprintf("users/%s/repos", url.PathEscape(owner)), nil, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subSearch {
+		// ). filter by prefix
+		subResult := []*Repo{}
+		for _, r := range result {
+			if strings.HasPrefix(r.FullPath, dd.Val(group)) {
+				subResult = append(subResult, r)
+			}
+		}
+		result = subResult
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%s Not find projects in %s", l.tag, dd.Val(group))
+	}
+	return result, nil
+}
+
+func (l *restList) Project(ctx context.Context, group, name *string) (*Repo, error) {
+	// ). get repository directly
+	p := new(restRepository)
+	path := fmt.Sprintf("repos/%s/%s", url.PathEscape(dd.Val(group)), url.PathEscape(dd.Val(name)))
+	if _, err := l.Get(ctx, path, nil, p); err != nil {
+		return nil, fmt.Errorf("%s Not find project[%s][%s] err[%s]", l.tag, dd.Val(group), dd.Val(name), err)
+	}
+	return l.convert(p), nil
+}
+
+// fetchRepositories - Walk all pages of |path| with |query|,
+// |wrapped| means repositories are wrapped in |data| of each page
+func (l *restList) fetchRepositories(ctx context.Context, path string, query url.Values, wrapped bool) ([]*Repo, error) {
+	l.Logger().Info(
+		l.tag,
+		"Waiting fetching repo...",
+		"[", "Path", "->", path, "]",
+	)
+
+	// ). prepare query
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set(l.pageParam, strconv.Itoa(l.pageSize))
+
+	// ). walk through all pages
+	result := []*Repo{}
+	next := path
+	for len(next) != 0 {
+		var ps []*restRepository
+		var n string
+		var err error
+		if wrapped {
+			sr := new(restSearchResult)
+			n, err = l.Get(ctx, next, query, sr)
+			ps = sr.Data
+		} else {
+			n, err = l.Get(ctx, next, query, &ps)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range ps {
+			if l.filterArchived && p.Archived {
+				continue
+			}
+			result = append(result, l.convert(p))
+		}
+		// next page link already carries the query
+		next, query = n, nil
+	}
+
+	l.Logger().Info(l.tag, "Done...")
+	return result, nil
+}
@@ -0,0 +1,200 @@
+package gitup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dannydd88/dd-go"
+	gitlabapi "gitlab.com/gitlab-org/api/client-go"
+)
+
+const (
+	TagRetry = "[retry]"
+
+	defaultRetryAttempts   = 3
+	defaultRetryBackoff    = 2 * time.Second
+	defaultRetryMaxBackoff = 30 * time.Second
+)
+
+// defaultRetryOn - errors of network blips and overloaded servers, other errors such as
+// auth failures or missing repos are never retried
+var defaultRetryOn = []string{
+	"timeout",
+	"timed out",
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"unexpected EOF",
+	"early EOF",
+	"temporary failure in name resolution",
+	"remote end hung up",
+	"unexpected disconnect",
+	"TLS handshake",
+	"too many requests",
+	"bad gateway",
+	"service unavailable",
+	"gateway timeout",
+	// status codes in git output, such as "The requested URL returned error: 502"
+	`re:(returned error|status code|HTTP):? (429|502|503|504)\b`,
+}
+
+// retryStatus - http status codes of api responses which are retried,
+// api errors with other status codes are never retried whatever the message is
+var retryStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+type RetryConfig struct {
+	// Attempts - total tries of one operation, 1 means no retry, 3 if not set
+	Attempts int
+	// Backoff - wait before the first retry such as "2s", doubled on each retry
+	Backoff *string
+	// MaxBackoff - upper bound of wait between retries such as "30s"
+	MaxBackoff *string
+	// RetryOn - patterns of retryable error messages, case insensitive substring
+	// or a regex with "re:" prefix, builtin network errors if not set
+	RetryOn []*string
+}
+
+// RetryPolicy - how transient failures of git and api operations are retried, nil means no retry
+type RetryPolicy struct {
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	retryOn    []*regexp.Regexp
+}
+
+// NewRetryPolicy
+// Helper function to create |RetryPolicy|, nil |config| means default policy
+func NewRetryPolicy(config *RetryConfig) (*RetryPolicy, error) {
+	if config == nil {
+		config = &RetryConfig{}
+	}
+	p := &RetryPolicy{
+		attempts:   config.Attempts,
+		backoff:    defaultRetryBackoff,
+		maxBackoff: defaultRetryMaxBackoff,
+	}
+	if p.attempts <= 0 {
+		p.attempts = defaultRetryAttempts
+	}
+
+	// ). parse backoff
+	var err error
+	if config.Backoff != nil {
+		if p.backoff, err = ParseDuration(dd.Val(config.Backoff)); err != nil {
+			return nil, err
+		}
+	}
+	if config.MaxBackoff != nil {
+		if p.maxBackoff, err = ParseDuration(dd.Val(config.MaxBackoff)); err != nil {
+			return nil, err
+		}
+	}
+	if p.maxBackoff < p.backoff {
+		p.maxBackoff = p.backoff
+	}
+
+	// ). compile retryable patterns
+	patterns := config.RetryOn
+	if len(patterns) == 0 {
+		patterns = dd.PtrSlice(defaultRetryOn)
+	}
+	for _, s := range patterns {
+		pattern := dd.Val(s)
+		if strings.HasPrefix(pattern, regexPrefix) {
+			pattern = "(?i)" + strings.TrimPrefix(pattern, regexPrefix)
+		} else {
+			pattern = "(?i)" + regexp.QuoteMeta(pattern)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s invalid pattern %s -> %s", TagRetry, dd.Val(s), err)
+		}
+		p.retryOn = append(p.retryOn, re)
+	}
+	return p, nil
+}
+
+// Retryable - Whether |err| looks like a transient failure
+func (p *RetryPolicy) Retryable(err error) bool {
+	if p == nil || err == nil {
+		return false
+	}
+	// cancelled or timeout by caller, retry does not help
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	if code := statusCode(err); code != 0 {
+		return slices.Contains(retryStatus, code)
+	}
+	return matchAny(p.retryOn, err.Error())
+}
+
+// statusCode - Http status code of api error |err|, 0 if |err| is not an api response
+func statusCode(err error) int {
+	var ge *gitlabapi.ErrorResponse
+	if errors.As(err, &ge) && ge.Response != nil {
+		return ge.Response.StatusCode
+	}
+	var re *restError
+	if errors.As(err, &re) {
+		return re.StatusCode
+	}
+	return 0
+}
+
+// Do - Run |fn| until it succeeds, fails with a non retryable error, runs out of attempts
+// or |ctx| is done while waiting,
+//
+//	|int| is how many times |fn| was retried, |name| is used for logging
+func (p *RetryPolicy) Do(ctx context.Context, logger dd.LevelLogger, name string, fn func() error) (int, error) {
+	err := fn()
+	if p == nil {
+		return 0, err
+	}
+	retries := 0
+	for ; err != nil && retries+1 < p.attempts && p.Retryable(err); retries++ {
+		wait := p.wait(retries)
+		logger.Warn(TagRetry, "Retry", "[", name, "]", "after", wait, "->", err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return retries, err
+		}
+		err = fn()
+	}
+	if err != nil && retries != 0 {
+		err = fmt.Errorf("%w (after %d retries)", err, retries)
+	}
+	return retries, err
+}
+
+// wait - Exponential backoff of the |n|th retry with up to 25% jitter,
+// so that workers failed together do not retry together
+func (p *RetryPolicy) wait(n int) time.Duration {
+	d := p.backoff
+	for i := 0; i < n && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, p.maxBackoff)
+	if d > 0 {
+		d += rand.N(d/4 + 1)
+	}
+	return d
+}
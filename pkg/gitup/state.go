@@ -0,0 +1,88 @@
+package gitup
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	stateDir  = ".gitup"
+	stateFile = "state.json"
+)
+
+// RepoState - what gitup knows about one synced repo
+type RepoState struct {
+	Path string `json:"path"`
+	URL  string `json:"url"`
+}
+
+// SyncState - local record of synced repos keyed by |Repo.ID|, lives under |cwd|
+type SyncState struct {
+	Repos map[string]*RepoState `json:"repos"`
+}
+
+// LoadSyncState - Load state under |cwd|, empty state if it does not exist yet
+func LoadSyncState(cwd *string) (*SyncState, error) {
+	s := &SyncState{
+		Repos: map[string]*RepoState{},
+	}
+	data, err := os.ReadFile(syncStatePath(cwd))
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Repos == nil {
+		s.Repos = map[string]*RepoState{}
+	}
+	return s, nil
+}
+
+// Save - Write state under |cwd|
+func (s *SyncState) Save(cwd *string) error {
+	path := syncStatePath(cwd)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write then rename, so that an interrupted run never leaves a broken state
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Get - Last known state of |r|, nil if unknown
+func (s *SyncState) Get(r *Repo) *RepoState {
+	if r.ID == 0 {
+		return nil
+	}
+	return s.Repos[strconv.Itoa(r.ID)]
+}
+
+// Put - Record |r|, repo without id is ignored
+func (s *SyncState) Put(r *Repo) {
+	if r.ID == 0 {
+		return
+	}
+	s.Repos[strconv.Itoa(r.ID)] = &RepoState{
+		Path: r.FullPath,
+		URL:  r.URL,
+	}
+}
+
+func syncStatePath(cwd *string) string {
+	return filepath.Join(dd.Val(cwd), stateDir, stateFile)
+}
@@ -0,0 +1,117 @@
+package gitup
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	TagStatic = "[static]"
+)
+
+// StaticRepo - one entry of a static repo manifest
+type StaticRepo struct {
+	URL   *string
+	Group *string
+	Name  *string
+}
+
+type StaticConfig struct {
+	Repos  []*StaticRepo
+	Logger dd.LevelLogger
+}
+
+// NewStaticList
+// Helper function to create |RepoList| implement from a fixed repo manifest,
+// |Group| and |Name| fallback to the path of |URL| when missing
+func NewStaticList(config *StaticConfig) (RepoList, error) {
+	s := &staticList{}
+	for i, sr := range config.Repos {
+		r, err := convertStaticRepo(sr)
+		if err != nil {
+			return nil, fmt.Errorf("%s invalid repo at index %d -> %s", TagStatic, i, err)
+		}
+		s.repos = append(s.repos, r)
+	}
+	config.Logger.Debug(TagStatic, "Load repos ->", len(s.repos))
+	return s, nil
+}
+
+type staticList struct {
+	repos []*Repo
+}
+
+func (s *staticList) Projects(ctx context.Context) ([]*Repo, error) {
+	return append([]*Repo{}, s.repos...), nil
+}
+
+func (s *staticList) ProjectsByGroup(ctx context.Context, group *string) ([]*Repo, error) {
+	result := []*Repo{}
+	for _, r := range s.repos {
+		if strings.HasPrefix(r.FullPath, dd.Val(group)) {
+			result = append(result, r)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%s Not find projects in %s", TagStatic, dd.Val(group))
+	}
+	return result, nil
+}
+
+func (s *staticList) Project(ctx context.Context, group, name *string) (*Repo, error) {
+	for _, r := range s.repos {
+		if r.Group == dd.Val(group) && r.Name == dd.Val(name) {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("%s Not find project[%s][%s]", TagStatic, dd.Val(group), dd.Val(name))
+}
+
+func convertStaticRepo(sr *StaticRepo) (*Repo, error) {
+	// ). check url
+	raw := strings.TrimSpace(dd.Val(sr.URL))
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("missing url")
+	}
+
+	// ). guess path from url, support scp-like "git@host:group/name.git" as well
+	p := raw
+	if u, err := url.Parse(raw); err == nil && len(u.Scheme) != 0 && len(u.Host) != 0 {
+		p = u.Path
+	} else if i := strings.Index(raw, ":"); i >= 0 {
+		p = raw[i+1:]
+	}
+	p = strings.Trim(strings.TrimSuffix(strings.Trim(p, "/"), ".git"), "/")
+
+	// ). decide group & name
+	name := strings.TrimSpace(dd.Val(sr.Name))
+	if len(name) == 0 {
+		name = path.Base(p)
+	}
+	group := strings.Trim(strings.TrimSpace(dd.Val(sr.Group)), "/")
+	if sr.Group == nil {
+		group = path.Dir(p)
+		if group == "." {
+			group = ""
+		}
+	}
+	if len(name) == 0 || name == "." || name == "/" {
+		return nil, fmt.Errorf("cannot decide name of %s", raw)
+	}
+
+	fullPath := name
+	if len(group) != 0 {
+		fullPath = group + "/" + name
+	}
+	return &Repo{
+		URL:      raw,
+		Name:     name,
+		Group:    group,
+		FullPath: fullPath,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package gitup
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dannydd88/dd-go"
+)
+
+func TestNewStaticListParsesManifest(t *testing.T) {
+	l, err := NewStaticList(&StaticConfig{
+		Repos: []*StaticRepo{
+			// group and name from url path, nested groups included
+			{URL: dd.Ptr("https://git.example/infra/tools/deploy.git")},
+			// scp-like url
+			{URL: dd.Ptr("git@git.example:acme/web.git")},
+			// ssh url with port
+			{URL: dd.Ptr("ssh://git@git.example:2222/acme/api.git/")},
+			// explicit group and name win over the url
+			{URL: dd.Ptr("https://git.example/x/y.git"), Group: dd.Ptr("/mirror/"), Name: dd.Ptr("z")},
+			// empty group puts the repo at the top
+			{URL: dd.Ptr("https://git.example/acme/top.git"), Group: dd.Ptr("")},
+		},
+		Logger: dd.NewLevelLogger(dd.ERROR),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repos, err := l.Projects(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range repos {
+		got = append(got, r.Group+"|"+r.Name+"|"+r.FullPath)
+	}
+	want := []string{
+		"infra/tools|deploy|infra/tools/deploy",
+		"acme|web|acme/web",
+		"acme|api|acme/api",
+		"mirror|z|mirror/z",
+		"|top|top",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("repos = %v, want %v", got, want)
+	}
+	if repos[1].URL != "git@git.example:acme/web.git" {
+		t.Errorf("url = %s, want it untouched", repos[1].URL)
+	}
+
+	// ). lookups by group prefix and by group and name
+	byGroup, err := l.ProjectsByGroup(context.Background(), dd.Ptr("acme"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fullPaths(byGroup), "acme/web,acme/api"; got != want {
+		t.Errorf("by group = %s, want %s", got, want)
+	}
+	if _, err := l.ProjectsByGroup(context.Background(), dd.Ptr("nope")); err == nil {
+		t.Error("missing group is listed")
+	}
+	if r, err := l.Project(context.Background(), dd.Ptr("infra/tools"), dd.Ptr("deploy")); err != nil || r.FullPath != "infra/tools/deploy" {
+		t.Errorf("project = %v, err = %v", r, err)
+	}
+	if _, err := l.Project(context.Background(), dd.Ptr("acme"), dd.Ptr("nope")); err == nil {
+		t.Error("missing project is found")
+	}
+}
+
+func TestNewStaticListRejectsInvalidRepo(t *testing.T) {
+	for _, sr := range []*StaticRepo{
+		{},
+		{URL: dd.Ptr("  ")},
+		{URL: dd.Ptr("https://git.example/")},
+	} {
+		_, err := NewStaticList(&StaticConfig{
+			Repos:  []*StaticRepo{{URL: dd.Ptr("https://git.example/acme/a.git")}, sr},
+			Logger: dd.NewLevelLogger(dd.ERROR),
+		})
+		if err == nil || !strings.Contains(err.Error(), "index 1") {
+			t.Errorf("url %q: err = %v, want invalid repo at index 1", dd.Val(sr.URL), err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package gitup
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+
+	"github.com/dannydd88/gitup/pkg/git"
+
+	"github.com/dannydd88/dd-go"
+)
+
+const (
+	TagStatus = "[status]"
+)
+
+type StatusConfig struct {
+	Groups  []*string
+	Backend *string
+	Matcher *RepoMatcher
+}
+
+// RepoStatus - local state of one repo in |Status|
+type RepoStatus struct {
+	Remote string
+	Repo   string
+	Path   string
+	// Status - nil if |Missing| or |Err| is set
+	Status *git.Status
+	// Missing - repo is listed but not cloned yet
+	Missing bool
+	Err     error
+}
+
+// Interesting - Whether the repo needs attention, a clean clone on its default branch does not
+func (r *RepoStatus) Interesting() bool {
+	if r.Missing || r.Err != nil {
+		return true
+	}
+	s := r.Status
+	return s.Dirty() ||
+		s.Ahead != 0 ||
+		s.Behind != 0 ||
+		s.Stashes != 0 ||
+		s.OffDefault() ||
+		(s.Detached() && len(s.Head) != 0)
+}
+
+// Status - collect local state of clones of repos listed by |Api| under |Cwd|
+type Status struct {
+	Api          RepoList
+	StatusConfig *StatusConfig
+	Cwd          *string
+	TaskRunner   dd.TaskRunner
+	Logger       dd.LevelLogger
+}
+
+// Go
+// Entrance of |status|, return state of each listed repo in listed order,
+// groups failed to list are returned with |Err|
+func (s *Status) Go(ctx context.Context) []*RepoStatus {
+	s.Logger.Info(TagStatus, "Started...")
+
+	// ). prepare repos
+	listing := listRepos(ctx, s.Api, s.StatusConfig.Groups, s.StatusConfig.Matcher, s.Logger, TagStatus)
+	results := []*RepoStatus{}
+	for _, f := range listing.failures {
+		results = append(results, &RepoStatus{
+			Repo: f.Repo,
+			Err:  f.Err,
+		})
+	}
+
+	// ). post status task to runner, reading local files only so no output in between
+	wg := new(sync.WaitGroup)
+	for _, repo := range listing.repos {
+		path := filepath.Join(dd.Val(s.Cwd), repo.FullPath)
+		result := &RepoStatus{
+			Repo: repo.FullPath,
+			Path: path,
+		}
+		results = append(results, result)
+		if !IsGitRepo(path) {
+			result.Missing = true
+			continue
+		}
+		if ctx.Err() != nil {
+			result.Err = cancelledError(ctx)
+			continue
+		}
+
+		wg.Add(1)
+		g := git.NewGit(s.Logger, s.StatusConfig.Backend, &git.GitConfig{
+			WorkDir: dd.Ptr(path),
+		})
+		c := dd.Bind5(doRepoStatus, ctx, g, repo, result, wg)
+		s.TaskRunner.Post(c)
+	}
+
+	s.Logger.Info(TagStatus, "Waiting repo status...")
+	wg.Wait()
+	s.Logger.Info(TagStatus, "Done...")
+	return results
+}
+
+func doRepoStatus(
+	ctx context.Context,
+	g git.Git,
+	repo *Repo,
+	result *RepoStatus,
+	wg *sync.WaitGroup,
+) error {
+	defer wg.Done()
+
+	// ). repo waiting in queue when cancelled is not started at all
+	if ctx.Err() != nil {
+		result.Err = cancelledError(ctx)
+		return result.Err
+	}
+
+	status, err := g.Status(ctx)
+	if err != nil {
+		result.Err = err
+		return err
+	}
+
+	// ). default branch known by provider is more reliable than local origin/HEAD
+	if len(repo.DefaultBranch) != 0 {
+		status.DefaultBranch = repo.DefaultBranch
+	}
+	result.Status = status
+	return nil
+}
@@ -3,8 +3,12 @@ package gitup
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/dannydd88/gitup/pkg/git"
 
@@ -13,12 +17,46 @@ import (
 
 const (
 	TagSync = "[sync]"
+
+	// ActionMove - repo is renamed or transferred and its existing clone will be moved
+	ActionMove = "move"
+	// ActionList - listing repos of a group, only appears in failed |RepoResult|
+	ActionList = "list"
+	// ListAll - |Repo| of failed |ActionList| result when listing all visible repos
+	ListAll = "*"
 )
 
 type SyncConfig struct {
-	Token  *string
-	Bare   bool
-	Groups []*string
+	Token        *string
+	Bare         bool
+	Groups       []*string
+	Transport    *string
+	SSH          *git.SSHConfig
+	Backend      *string
+	Depth        int
+	SingleBranch bool
+	Filter       *string
+	Matcher      *RepoMatcher
+	// Prune - what to do with orphaned clones, report | trash | delete, report if not set
+	Prune *string
+	// ConfirmPrune - asked before deleting orphaned clones, deletion is cancelled if it returns false
+	ConfirmPrune func(paths []string) bool
+	// Retry - retry policy of git operations, nil means no retry
+	Retry *RetryPolicy
+	// Limiter - rate and concurrency limit of git operations per host, nil means unlimited
+	Limiter *HostLimiter
+	// RepoTimeout - timeout of syncing one repo including retries, 0 means no timeout
+	RepoTimeout time.Duration
+}
+
+// SyncPlan - what |Sync| would do to one repo
+type SyncPlan struct {
+	Remote string `json:"remote,omitempty"`
+	Repo   string `json:"repo"`
+	URL    string `json:"url"`
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
 }
 
 // Sync
@@ -28,50 +66,83 @@ type Sync struct {
 	Cwd        *string
 	TaskRunner dd.TaskRunner
 	Logger     dd.LevelLogger
+	// Progress - live progress of repos, nil means plain log lines only
+	Progress Progress
 }
 
 // Go
-// Entrance of |sync|
-func (s *Sync) Go() {
+// Entrance of |sync|, return what happened to each repo,
+// once |ctx| is done no more repo is started and running ones are cancelled
+func (s *Sync) Go(ctx context.Context) []*RepoResult {
 	s.Logger.Info(TagSync, "Started...")
 
 	// ). prepare repos
-	var repos []*Repo
-	if len(s.SyncConfig.Groups) == 0 {
-		repos = s.Api.Projects()
-	} else {
-		repos = []*Repo{}
-		for _, g := range s.SyncConfig.Groups {
-			result, err := s.Api.ProjectsByGroup(g)
-			if err != nil {
-				s.Logger.Warn(TagSync, "Meet error ->", err)
-				continue
-			} else {
-				repos = append(repos, result...)
-			}
-		}
+	listing := s.listRepos(ctx)
+	repos := listing.repos
+
+	// ). move clones of renamed or transferred repos
+	state := s.loadState()
+	if state != nil {
+		s.relocate(state, repos)
 	}
 
 	// ). prepare context
-	ctx, cancel := context.WithCancel(context.Background())
+	waitCtx, cancel := context.WithCancel(context.Background())
 	output := make(chan string)
 	defer close(output)
 	wg := new(sync.WaitGroup)
 
 	s.Logger.Info(TagSync, "Start sync repos ->", len(repos))
+	if len(dd.Val(s.SyncConfig.Filter)) != 0 && !strings.EqualFold(dd.Val(s.SyncConfig.Backend), git.BackendCLI) {
+		s.Logger.Warn(TagSync, "Filter is only supported by cli backend, ignore ->", dd.Val(s.SyncConfig.Filter))
+	}
 
 	// ). post git task to runner
+	results := append([]*RepoResult{}, listing.failures...)
+	s.progress().Start(len(repos))
 	for _, repo := range repos {
 		wg.Add(1)
 		url := dd.Ptr(repo.URL)
-		path := dd.Ptr(filepath.Join(dd.Val(s.Cwd), repo.FullPath))
-		git := git.NewGoGit(s.Logger, &git.GitConfig{
-			URL:     url,
-			WorkDir: path,
-			Bare:    s.SyncConfig.Bare,
-			Token:   s.SyncConfig.Token,
+		path := s.repoPath(repo)
+		action, reason := git.SyncAction(path, s.SyncConfig.Bare)
+		result := &RepoResult{
+			Repo:   repo.FullPath,
+			Path:   dd.Val(path),
+			Action: action,
+		}
+		results = append(results, result)
+		if ctx.Err() != nil {
+			result.Err = cancelledError(ctx)
+			s.progress().Finish(result.Repo, result.Err)
+			wg.Done()
+			continue
+		}
+		if action == git.ActionSkip {
+			// ). path is occupied, leave it untouched as |Plan| reports
+			s.Logger.Warn(TagSync, "Skip sync", "[", dd.Val(path), "]", reason)
+			result.Skipped = reason
+			s.progress().Finish(result.Repo, nil)
+			wg.Done()
+			continue
+		}
+		var progress io.Writer
+		if s.Progress != nil {
+			progress = NewProgressWriter(s.Progress, repo.FullPath)
+		}
+		git := git.NewGit(s.Logger, s.SyncConfig.Backend, &git.GitConfig{
+			URL:          url,
+			SSHURL:       dd.Ptr(repo.SSHURL),
+			WorkDir:      path,
+			Bare:         s.SyncConfig.Bare,
+			Token:        s.SyncConfig.Token,
+			Transport:    s.SyncConfig.Transport,
+			SSH:          s.SyncConfig.SSH,
+			Depth:        s.SyncConfig.Depth,
+			SingleBranch: s.SyncConfig.SingleBranch,
+			Filter:       s.SyncConfig.Filter,
+			Progress:     progress,
 		})
-		c := dd.Bind3(doSyncGitRepo, git, output, wg)
+		c := dd.Bind6(s.doSyncGitRepo, ctx, git, s.repoHost(repo), result, output, wg)
 		s.TaskRunner.Post(c)
 	}
 
@@ -87,15 +158,263 @@ func (s *Sync) Go() {
 		select {
 		case m := <-output:
 			s.Logger.Info(m)
-		case <-ctx.Done():
+		case <-waitCtx.Done():
 			s.Logger.Info(TagSync, "Done...")
 			alive = false
 		}
 	}
+
+	// ). record repos for next run
+	if state != nil {
+		for _, r := range repos {
+			state.Put(r)
+		}
+		if err := state.Save(s.Cwd); err != nil {
+			s.Logger.Warn(TagSync, "Save state error ->", err)
+		}
+	}
+
+	// ). report or prune local clones no longer listed, but never touch disk after cancelled
+	if ctx.Err() == nil {
+		results = append(results, s.prune(listing)...)
+	}
+
+	return results
+}
+
+// Plan
+// Resolve repos and compare with disk, report what |Go| would do without touching network for git,
+// groups failed to list are reported as |ActionList|, listing stops once |ctx| is done
+func (s *Sync) Plan(ctx context.Context) []*SyncPlan {
+	result := []*SyncPlan{}
+	state := s.loadState()
+	listing := s.listRepos(ctx)
+	for _, f := range listing.failures {
+		result = append(result, &SyncPlan{
+			Repo:   f.Repo,
+			Action: f.Action,
+			Reason: f.Err.Error(),
+		})
+	}
+	for _, repo := range listing.repos {
+		path := s.repoPath(repo)
+		action, reason := git.SyncAction(path, s.SyncConfig.Bare)
+		if state != nil {
+			if from := s.movedFrom(state, repo); len(from) != 0 {
+				action, reason = ActionMove, "from "+from
+			}
+		}
+		result = append(result, &SyncPlan{
+			Repo:   repo.FullPath,
+			URL:    repo.URL,
+			Path:   dd.Val(path),
+			Action: action,
+			Reason: reason,
+		})
+	}
+	for _, o := range s.findOrphans(listing) {
+		result = append(result, &SyncPlan{
+			Repo:   o,
+			Path:   filepath.Join(dd.Val(s.Cwd), o),
+			Action: ActionOrphan,
+			Reason: s.pruneMode(),
+		})
+	}
+	return result
+}
+
+// syncListing - repos listed by |Api| for one run
+type syncListing struct {
+	// all - every listed repo, before matcher
+	all []*Repo
+	// repos - repos selected by matcher
+	repos []*Repo
+	// roots - groups listed without error, empty string means the whole |Cwd|
+	roots []string
+	// failures - groups failed to list
+	failures []*RepoResult
+	// complete - every group is listed without error, orphans are only detected in a complete listing
+	complete bool
+}
+
+func (s *Sync) listRepos(ctx context.Context) *syncListing {
+	return listRepos(ctx, s.Api, s.SyncConfig.Groups, s.SyncConfig.Matcher, s.Logger, TagSync)
+}
+
+// listRepos - List |groups| of |api|, all visible repos if no group, then apply |matcher|,
+// |tag| is used for logging, listing stops once |ctx| is done
+func listRepos(ctx context.Context, api RepoList, groups []*string, matcher *RepoMatcher, logger dd.LevelLogger, tag string) *syncListing {
+	// ). list by groups
+	l := &syncListing{}
+	if len(groups) == 0 {
+		all, err := api.Projects(ctx)
+		if err != nil {
+			logger.Warn(tag, "Meet error ->", err)
+			l.failures = append(l.failures, &RepoResult{
+				Repo:   ListAll,
+				Action: ActionList,
+				Err:    err,
+			})
+		} else {
+			l.all = all
+			l.roots = []string{""}
+		}
+	} else {
+		l.all = []*Repo{}
+		for _, g := range groups {
+			result, err := api.ProjectsByGroup(ctx, g)
+			if err != nil {
+				logger.Warn(tag, "Meet error ->", err)
+				l.failures = append(l.failures, &RepoResult{
+					Repo:   dd.Val(g),
+					Action: ActionList,
+					Err:    err,
+				})
+				continue
+			} else {
+				l.all = append(l.all, result...)
+				l.roots = append(l.roots, dd.Val(g))
+			}
+		}
+	}
+
+	l.complete = len(l.failures) == 0
+
+	// ). apply include & exclude patterns
+	l.repos = l.all
+	if matcher != nil {
+		l.repos = matcher.Filter(l.all)
+		logger.Info(tag, "Filter repos ->", len(l.repos), "/", len(l.all))
+	}
+	return l
+}
+
+// loadState - nil if state is broken, so that it is neither used nor overwritten
+func (s *Sync) loadState() *SyncState {
+	state, err := LoadSyncState(s.Cwd)
+	if err != nil {
+		s.Logger.Warn(TagSync, "Load state error, skip rename detection ->", err)
+		return nil
+	}
+	return state
+}
+
+// movedFrom - Old path of an existing clone of |repo| if it has been renamed or transferred,
+// empty if nothing to move
+func (s *Sync) movedFrom(state *SyncState, repo *Repo) string {
+	old := state.Get(repo)
+	if old == nil || old.Path == repo.FullPath {
+		return ""
+	}
+
+	// ). old clone should still exist
+	from := filepath.Join(dd.Val(s.Cwd), old.Path)
+	if action, _ := git.SyncAction(&from, s.SyncConfig.Bare); action != git.ActionFetch && action != git.ActionPull {
+		return ""
+	}
+
+	// ). new path should be free
+	if action, _ := git.SyncAction(s.repoPath(repo), s.SyncConfig.Bare); action != git.ActionClone {
+		s.Logger.Warn(TagSync, "Renamed repo target is occupied, skip move ->", from, "->", repo.FullPath)
+		return ""
+	}
+	return from
+}
+
+// relocate - Move existing clones of renamed or transferred repos to their new path
+func (s *Sync) relocate(state *SyncState, repos []*Repo) {
+	for _, r := range repos {
+		from := s.movedFrom(state, r)
+		if len(from) == 0 {
+			continue
+		}
+		to := s.repoPath(r)
+
+		// ). remove possible empty target directory, then move
+		os.Remove(dd.Val(to))
+		if err := os.MkdirAll(filepath.Dir(dd.Val(to)), os.ModePerm); err != nil {
+			s.Logger.Warn(TagSync, "Move repo error ->", from, err)
+			continue
+		}
+		if err := os.Rename(from, dd.Val(to)); err != nil {
+			s.Logger.Warn(TagSync, "Move repo error ->", from, err)
+			continue
+		}
+		// old group directory is removed only when it becomes empty
+		os.Remove(filepath.Dir(from))
+
+		// ). point origin to the new url, old one may not redirect
+		if err := git.SetRemoteURL(to, "origin", r.URL); err != nil {
+			s.Logger.Warn(TagSync, "Update remote url error ->", dd.Val(to), err)
+		}
+		s.Logger.Info(TagSync, "Move renamed repo", "[", from, "]->[", dd.Val(to), "]")
+	}
+}
+
+func (s *Sync) progress() Progress {
+	if s.Progress == nil {
+		return nopProgress{}
+	}
+	return s.Progress
+}
+
+func (s *Sync) repoPath(repo *Repo) *string {
+	return dd.Ptr(filepath.Join(dd.Val(s.Cwd), repo.FullPath))
+}
+
+// repoHost - Host which git operations of |repo| talk to
+func (s *Sync) repoHost(repo *Repo) string {
+	if strings.EqualFold(dd.Val(s.SyncConfig.Transport), git.TransportSSH) && len(repo.SSHURL) != 0 {
+		return hostOf(repo.SSHURL)
+	}
+	return hostOf(repo.URL)
 }
 
-func doSyncGitRepo(g git.Git, output chan string, wg *sync.WaitGroup) error {
-	updated, err := g.Sync()
+func (s *Sync) doSyncGitRepo(
+	ctx context.Context,
+	g git.Git,
+	host string,
+	result *RepoResult,
+	output chan string,
+	wg *sync.WaitGroup,
+) error {
+	defer wg.Done()
+	defer func() {
+		s.progress().Finish(result.Repo, result.Err)
+	}()
+
+	// ). repo waiting in queue when cancelled is not started at all
+	if ctx.Err() != nil {
+		result.Err = cancelledError(ctx)
+		return result.Err
+	}
+	s.progress().Begin(result.Repo, result.Action)
+	if s.SyncConfig.RepoTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.SyncConfig.RepoTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	var updated bool
+	retries, err := s.SyncConfig.Retry.Do(ctx, s.Logger, dd.Val(g.Path()), func() error {
+		// ). wait for a free slot and rate limit of |host|
+		release, err := s.SyncConfig.Limiter.Acquire(ctx, host)
+		if err != nil {
+			return err
+		}
+		defer release()
+		if err := s.SyncConfig.Limiter.Wait(ctx, host); err != nil {
+			return err
+		}
+
+		updated, err = g.Sync(ctx)
+		return err
+	})
+	result.Updated = updated
+	result.Err = err
+	result.Retries = retries
+	result.Duration = time.Since(start)
 
 	var msg string
 	if err == nil {
@@ -121,7 +440,11 @@ func doSyncGitRepo(g git.Git, output chan string, wg *sync.WaitGroup) error {
 	}
 
 	output <- msg
-	wg.Done()
 
 	return err
 }
+
+// cancelledError - Error of repo which is not started since |ctx| is done
+func cancelledError(ctx context.Context) error {
+	return fmt.Errorf("not started: %w", ctx.Err())
+}